@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"reflect"
+	"strings"
+)
+
+const (
+	beforeKey = "audit_before"
+	afterKey  = "audit_after"
+	targetKey = "audit_target"
+)
+
+// Redacted replaces sensitive values in stored snapshots and request
+// bodies.
+const Redacted = "[redacted]"
+
+type Target struct {
+	Type string
+	ID   string
+}
+
+// SetBefore records the state of the entity a handler is about to change.
+// The audit middleware stores it with the request's log entry.
+func SetBefore(c *gin.Context, entity interface{}) {
+	c.Set(beforeKey, Snapshot(entity))
+}
+
+// SetAfter records the state of the entity once the handler changed it.
+func SetAfter(c *gin.Context, entity interface{}) {
+	c.Set(afterKey, Snapshot(entity))
+}
+
+// SetTarget overrides the target derived from the route, e.g. for
+// endpoints that create an entity.
+func SetTarget(c *gin.Context, targetType string, targetID string) {
+	c.Set(targetKey, Target{Type: targetType, ID: targetID})
+}
+
+func Before(c *gin.Context) map[string]interface{} {
+	return snapshotFrom(c, beforeKey)
+}
+
+func After(c *gin.Context) map[string]interface{} {
+	return snapshotFrom(c, afterKey)
+}
+
+func TargetOf(c *gin.Context) (Target, bool) {
+	value, exists := c.Get(targetKey)
+	if !exists {
+		return Target{}, false
+	}
+
+	target, ok := value.(Target)
+	return target, ok
+}
+
+func snapshotFrom(c *gin.Context, key string) map[string]interface{} {
+	value, exists := c.Get(key)
+	if !exists {
+		return nil
+	}
+
+	snapshot, _ := value.(map[string]interface{})
+	return snapshot
+}
+
+// Snapshot flattens an entity to its JSON fields with secrets redacted.
+func Snapshot(entity interface{}) map[string]interface{} {
+	if entity == nil || (reflect.ValueOf(entity).Kind() == reflect.Ptr && reflect.ValueOf(entity).IsNil()) {
+		return nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+
+	return Redact(snapshot).(map[string]interface{})
+}
+
+// Redact walks decoded JSON and hides values whose keys look sensitive.
+func Redact(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range typed {
+			if sensitiveKey(key) {
+				typed[key] = Redacted
+				continue
+			}
+			typed[key] = Redact(nested)
+		}
+	case []interface{}:
+		for i, nested := range typed {
+			typed[i] = Redact(nested)
+		}
+	}
+
+	return value
+}
+
+func sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+
+	// Gift card codes spend like cash; coupon codes share the key and are
+	// hidden along with them.
+	if key == "code" {
+		return true
+	}
+
+	for _, fragment := range []string{"password", "secret", "token", "card_number", "cvv"} {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Diff lists the top-level fields that differ between two snapshots. It is
+// nil for creations and deletions, where one snapshot says it all.
+func Diff(before map[string]interface{}, after map[string]interface{}) map[string]Change {
+	if before == nil || after == nil {
+		return nil
+	}
+
+	changes := map[string]Change{}
+	for key, from := range before {
+		if to, ok := after[key]; !ok || !reflect.DeepEqual(from, to) {
+			changes[key] = Change{From: from, To: after[key]}
+		}
+	}
+	for key, to := range after {
+		if _, ok := before[key]; !ok {
+			changes[key] = Change{To: to}
+		}
+	}
+
+	// Timestamps change on every save and only add noise.
+	delete(changes, "UpdatedAt")
+	delete(changes, "updated_at")
+
+	return changes
+}
@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"api/database"
+	"api/models"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func GetCustomerAddresses(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var addresses []models.Address
+	if err := database.GetDB().Where("customer_id = ?", customerId).Order("created_at").Find(&addresses).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(addresses) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No addresses found for this customer")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, addresses)
+}
+
+func GetCustomerAddress(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var address models.Address
+	if err := database.GetDB().Where("customer_id = ?", customerId).First(&address, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Address not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, address)
+}
+
+func CreateCustomerAddress(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var input struct {
+		Label             string               `json:"label" binding:"omitempty"`
+		Address           models.AddressFields `json:"address" binding:"required"`
+		IsDefaultShipping bool                 `json:"is_default_shipping"`
+		IsDefaultBilling  bool                 `json:"is_default_billing"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	fields := utils.NormalizeAddress(input.Address)
+	if errs := utils.ValidateAddress(fields); len(errs) > 0 {
+		utils.AddressValidationErrorJson(c, errs)
+		return
+	}
+
+	address := models.Address{
+		CustomerID:        customerId.(uint),
+		Label:             input.Label,
+		Fields:            fields,
+		IsDefaultShipping: input.IsDefaultShipping,
+		IsDefaultBilling:  input.IsDefaultBilling,
+	}
+
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		return saveCustomerAddress(tx, &address)
+	}); err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, address)
+}
+
+func UpdateCustomerAddress(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var address models.Address
+	if err := database.GetDB().Where("customer_id = ?", customerId).First(&address, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Address not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	var input struct {
+		Label             string                `json:"label" binding:"omitempty"`
+		Address           *models.AddressFields `json:"address" binding:"omitempty"`
+		IsDefaultShipping *bool                 `json:"is_default_shipping" binding:"omitempty"`
+		IsDefaultBilling  *bool                 `json:"is_default_billing" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.Label != "" {
+		address.Label = input.Label
+	}
+	if input.Address != nil {
+		fields := utils.NormalizeAddress(*input.Address)
+		if errs := utils.ValidateAddress(fields); len(errs) > 0 {
+			utils.AddressValidationErrorJson(c, errs)
+			return
+		}
+		address.Fields = fields
+	}
+	if input.IsDefaultShipping != nil {
+		address.IsDefaultShipping = *input.IsDefaultShipping
+	}
+	if input.IsDefaultBilling != nil {
+		address.IsDefaultBilling = *input.IsDefaultBilling
+	}
+
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		return saveCustomerAddress(tx, &address)
+	}); err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, address)
+}
+
+func DeleteCustomerAddress(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var address models.Address
+	if err := database.GetDB().Where("customer_id = ?", customerId).First(&address, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Address not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&address).Error; err != nil {
+			return err
+		}
+
+		return promoteDefaultAddress(tx, address)
+	})
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Address deleted successfully"})
+}
+
+func saveCustomerAddress(tx *gorm.DB, address *models.Address) error {
+	var count int64
+	if err := tx.Model(&models.Address{}).Where("customer_id = ? AND id <> ?", address.CustomerID, address.ID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		address.IsDefaultShipping = true
+		address.IsDefaultBilling = true
+	}
+
+	if err := tx.Save(address).Error; err != nil {
+		return err
+	}
+
+	if address.IsDefaultShipping {
+		if err := tx.Model(&models.Address{}).Where("customer_id = ? AND id <> ?", address.CustomerID, address.ID).Update("is_default_shipping", false).Error; err != nil {
+			return err
+		}
+	}
+
+	if address.IsDefaultBilling {
+		if err := tx.Model(&models.Address{}).Where("customer_id = ? AND id <> ?", address.CustomerID, address.ID).Update("is_default_billing", false).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// promoteDefaultAddress hands the defaults of a deleted address to the
+// customer's oldest remaining address.
+func promoteDefaultAddress(tx *gorm.DB, deleted models.Address) error {
+	if !deleted.IsDefaultShipping && !deleted.IsDefaultBilling {
+		return nil
+	}
+
+	var next models.Address
+	if err := tx.Where("customer_id = ?", deleted.CustomerID).Order("id").First(&next).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	updates := map[string]interface{}{}
+	if deleted.IsDefaultShipping {
+		updates["is_default_shipping"] = true
+	}
+	if deleted.IsDefaultBilling {
+		updates["is_default_billing"] = true
+	}
+
+	return tx.Model(&next).Updates(updates).Error
+}
+
+func resolveShippingAddress(c *gin.Context, customerId interface{}, addressID *uint, inline *models.AddressFields) (models.AddressFields, bool) {
+	if inline != nil {
+		fields := utils.NormalizeAddress(*inline)
+		if errs := utils.ValidateAddress(fields); len(errs) > 0 {
+			utils.AddressValidationErrorJson(c, errs)
+			return fields, false
+		}
+
+		return fields, true
+	}
+
+	query := database.GetDB().Where("customer_id = ?", customerId)
+	if addressID != nil {
+		query = query.Where("id = ?", *addressID)
+	} else {
+		query = query.Where("is_default_shipping = ?", true)
+	}
+
+	var address models.Address
+	if err := query.First(&address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.BadRequestErrorJson(c, "A shipping address is required, pass address_id or shipping_address")
+			return address.Fields, false
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return address.Fields, false
+	}
+
+	return address.Fields, true
+}
+
+func resolveBillingAddress(c *gin.Context, customerId interface{}, addressID *uint, shippingAddress models.AddressFields) (models.AddressFields, bool) {
+	query := database.GetDB().Where("customer_id = ?", customerId)
+	if addressID != nil {
+		query = query.Where("id = ?", *addressID)
+	} else {
+		query = query.Where("is_default_billing = ?", true)
+	}
+
+	var address models.Address
+	if err := query.First(&address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if addressID != nil {
+				utils.NotFoundRequestErrorJson(c, "Billing address not found")
+				return address.Fields, false
+			}
+
+			return shippingAddress, true
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return address.Fields, false
+	}
+
+	return address.Fields, true
+}
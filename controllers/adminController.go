@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"api/audit"
 	"api/database"
 	"api/models"
 	"api/utils"
@@ -9,6 +10,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 	"net/http"
+	"strconv"
 )
 
 func CreateAdmin(c *gin.Context) {
@@ -71,6 +73,8 @@ func CreateAdmin(c *gin.Context) {
 		return
 	}
 
+	audit.SetTarget(c, "admins", strconv.FormatUint(uint64(newAdmin.ID), 10))
+	audit.SetAfter(c, newAdmin)
 	utils.JSONResponse(c, http.StatusCreated, newAdmin)
 }
 
@@ -125,6 +129,8 @@ func UpdateAdmin(c *gin.Context) {
 		return
 	}
 
+	audit.SetBefore(c, existingAdmin)
+
 	var adminInput struct {
 		Email    string `json:"email" binding:"omitempty,email"`
 		Name     string `json:"name" binding:"omitempty"`
@@ -188,5 +194,6 @@ func UpdateAdmin(c *gin.Context) {
 		return
 	}
 
+	audit.SetAfter(c, existingAdmin)
 	utils.JSONResponse(c, http.StatusOK, existingAdmin)
 }
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"api/database"
+	"api/models"
+	"api/utils"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var auditLogExportColumns = []string{"id", "created_at", "request_id", "actor_type", "actor_id", "action", "target_type", "target_id", "method", "path", "status_code", "ip", "user_agent", "diff"}
+
+func GetAuditLogs(c *gin.Context) {
+	query, ok := auditLogQuery(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		utils.BadRequestErrorJson(c, "limit must be between 1 and 500")
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		utils.BadRequestErrorJson(c, "offset must be zero or more")
+		return
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(entries) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No audit log entries found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, entries)
+}
+
+func GetAuditLog(c *gin.Context) {
+	var entry models.AuditLog
+	if err := database.GetDB().First(&entry, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Audit log entry not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, entry)
+}
+
+// ExportAuditLogs streams every entry matching the filters as CSV or JSON
+// Lines, oldest first.
+func ExportAuditLogs(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", utils.FileFormatCSV))
+	if format != utils.FileFormatCSV && format != utils.FileFormatJSONL {
+		utils.BadRequestErrorJson(c, "format must be csv or jsonl")
+		return
+	}
+
+	query, ok := auditLogQuery(c)
+	if !ok {
+		return
+	}
+
+	rows, err := query.Model(&models.AuditLog{}).Order("id").Rows()
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("audit-log-%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	var encoder *json.Encoder
+	var writer *csv.Writer
+	if format == utils.FileFormatJSONL {
+		c.Header("Content-Type", "application/x-ndjson")
+		encoder = json.NewEncoder(c.Writer)
+	} else {
+		c.Header("Content-Type", "text/csv")
+		writer = csv.NewWriter(c.Writer)
+		defer writer.Flush()
+	}
+	c.Status(http.StatusOK)
+
+	if writer != nil {
+		if err := writer.Write(auditLogExportColumns); err != nil {
+			log.Printf("Could not write audit log export: %v", err)
+			return
+		}
+	}
+
+	for rows.Next() {
+		var entry models.AuditLog
+		if err := database.GetDB().ScanRows(rows, &entry); err != nil {
+			log.Printf("Could not read audit log export: %v", err)
+			return
+		}
+
+		if encoder != nil {
+			err = encoder.Encode(entry)
+		} else {
+			err = writer.Write(auditLogRecord(entry))
+		}
+		if err != nil {
+			log.Printf("Could not write audit log export: %v", err)
+			return
+		}
+	}
+}
+
+func auditLogRecord(entry models.AuditLog) []string {
+	actorID := ""
+	if entry.ActorID != nil {
+		actorID = strconv.FormatUint(uint64(*entry.ActorID), 10)
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(entry.ID), 10),
+		entry.CreatedAt.Format(time.RFC3339),
+		entry.RequestID,
+		entry.ActorType,
+		actorID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.Method,
+		entry.Path,
+		strconv.Itoa(entry.StatusCode),
+		entry.IP,
+		entry.UserAgent,
+		entry.Diff,
+	}
+}
+
+// auditLogQuery applies the filters shared by the list and export
+// endpoints. from and to are RFC 3339 timestamps.
+func auditLogQuery(c *gin.Context) (*gorm.DB, bool) {
+	query := database.GetDB()
+
+	filters := []struct {
+		param  string
+		column string
+	}{
+		{"actor_type", "actor_type"},
+		{"actor_id", "actor_id"},
+		{"action", "action"},
+		{"target_type", "target_type"},
+		{"target_id", "target_id"},
+		{"request_id", "request_id"},
+		{"method", "method"},
+		{"ip", "ip"},
+	}
+	for _, filter := range filters {
+		if value := c.Query(filter.param); value != "" {
+			query = query.Where(filter.column+" = ?", value)
+		}
+	}
+
+	if c.Query("failed") == "true" {
+		query = query.Where("status_code >= ?", http.StatusBadRequest)
+	}
+
+	bounds := []struct {
+		param    string
+		operator string
+	}{
+		{"from", ">="},
+		{"to", "<"},
+	}
+	for _, bound := range bounds {
+		value := c.Query(bound.param)
+		if value == "" {
+			continue
+		}
+
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.BadRequestErrorJson(c, bound.param+" must be an RFC 3339 timestamp")
+			return nil, false
+		}
+		query = query.Where("created_at "+bound.operator+" ?", at)
+	}
+
+	return query, true
+}
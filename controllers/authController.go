@@ -66,6 +66,10 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	if userType == "customer" && !mergeGuestCart(c, userID) {
+		return
+	}
+
 	token, err := utils.GenerateJWT(userID, userType)
 	if err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
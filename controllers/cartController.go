@@ -3,24 +3,30 @@ package controllers
 import (
 	"api/database"
 	"api/models"
+	"api/services"
 	"api/utils"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
-func AddItemToCart(c *gin.Context) {
-	customerID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+func GetCart(c *gin.Context) {
+	cart, ok := findActiveCart(c, false)
+	if !ok {
 		return
 	}
 
+	respondWithPricedCart(c, &cart, http.StatusOK)
+}
+
+func AddItemToCart(c *gin.Context) {
 	var input struct {
 		ProductID uint `json:"product_id" binding:"required"`
-		Quantity  int  `json:"quantity" binding:"required"`
+		Quantity  int  `json:"quantity" binding:"required,gt=0"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -35,7 +41,7 @@ func AddItemToCart(c *gin.Context) {
 	}
 
 	var product models.Product
-	if err := database.GetDB().First(&product, input.ProductID).Error; err != nil {
+	if err := services.VisibleProducts(database.GetDB()).First(&product, input.ProductID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.NotFoundRequestErrorJson(c, "Product not found")
 			return
@@ -45,53 +51,46 @@ func AddItemToCart(c *gin.Context) {
 		return
 	}
 
-	var cart models.Cart
-	if err := database.GetDB().Where("customer_id = ? AND is_active = ?", customerID, true).First(&cart).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			cart = models.Cart{
-				CustomerID: customerID.(uint),
-				IsActive:   true,
-			}
-			database.GetDB().Create(&cart)
-		} else {
-			utils.InternalServerErrorJSON(c, err.Error())
-			return
-		}
+	cart, ok := findActiveCart(c, true)
+	if !ok {
+		return
 	}
 
-	var cartItem models.CartItem
-	if err := database.GetDB().Where("product_id = ? AND cart_id = ?", product.ID, cart.ID).First(&cartItem).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var cartItem models.CartItem
+		if err := tx.Where("product_id = ? AND cart_id = ?", product.ID, cart.ID).First(&cartItem).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
 			cartItem = models.CartItem{
 				CartID:    cart.ID,
 				ProductID: input.ProductID,
 				Quantity:  input.Quantity,
 			}
-			database.GetDB().Create(&cartItem)
+			if err := tx.Create(&cartItem).Error; err != nil {
+				return err
+			}
 		} else {
-			utils.InternalServerErrorJSON(c, err.Error())
-			return
+			cartItem.Quantity += input.Quantity
+			if err := tx.Save(&cartItem).Error; err != nil {
+				return err
+			}
 		}
-	} else {
-		cartItem.Quantity += input.Quantity
-		database.GetDB().Save(&cartItem)
-	}
 
-	cart.TotalPrice += float64(input.Quantity) * product.Price
-	database.GetDB().Save(&cart)
+		return services.TouchCart(tx, cart.ID)
+	})
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
 
-	utils.JSONResponse(c, http.StatusCreated, gin.H{"message": "Product successfully added to cart"})
+	respondWithPricedCart(c, &cart, http.StatusCreated)
 }
 
 func UpdateCartItem(c *gin.Context) {
-	customerID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
-		return
-	}
-
 	var input struct {
-		Quantity int `json:"quantity" binding:"required"`
+		Quantity int `json:"quantity" binding:"required,gt=0"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -105,10 +104,15 @@ func UpdateCartItem(c *gin.Context) {
 		return
 	}
 
-	var cart models.Cart
-	if err := database.GetDB().Where("customer_id = ? AND is_active = ?", customerID, true).First(&cart).Error; err != nil {
+	cart, ok := findActiveCart(c, false)
+	if !ok {
+		return
+	}
+
+	var cartItem models.CartItem
+	if err := database.GetDB().Where("cart_id = ?", cart.ID).First(&cartItem, c.Param("cartItemId")).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			utils.NotFoundRequestErrorJson(c, "Cart not found")
+			utils.NotFoundRequestErrorJson(c, "Cart item not found")
 			return
 		}
 
@@ -116,8 +120,30 @@ func UpdateCartItem(c *gin.Context) {
 		return
 	}
 
+	cartItem.Quantity = input.Quantity
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&cartItem).Error; err != nil {
+			return err
+		}
+
+		return services.TouchCart(tx, cart.ID)
+	})
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	respondWithPricedCart(c, &cart, http.StatusOK)
+}
+
+func DeleteCartItem(c *gin.Context) {
+	cart, ok := findActiveCart(c, false)
+	if !ok {
+		return
+	}
+
 	var cartItem models.CartItem
-	if err := database.GetDB().First(&cartItem, c.Param("cartItemId")).Error; err != nil {
+	if err := database.GetDB().Where("cart_id = ?", cart.ID).First(&cartItem, c.Param("cartItemId")).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.NotFoundRequestErrorJson(c, "Cart item not found")
 			return
@@ -127,20 +153,146 @@ func UpdateCartItem(c *gin.Context) {
 		return
 	}
 
-	cartItem.Quantity = input.Quantity
-	if err := database.GetDB().Save(&cartItem).Error; err != nil {
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&cartItem).Error; err != nil {
+			return err
+		}
+
+		return services.TouchCart(tx, cart.ID)
+	})
+	if err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
-	utils.JSONResponse(c, http.StatusOK, cartItem)
+	respondWithPricedCart(c, &cart, http.StatusOK)
 }
 
-func DeleteCartItem(c *gin.Context) {
-	var cartItem models.Customer
-	if err := database.GetDB().First(&cartItem, c.Param("cartItemId")).Error; err != nil {
+// findActiveCart falls back to the X-Cart-Token guest cart on guest routes;
+// new guest carts get their token back in that header.
+func findActiveCart(c *gin.Context, create bool) (models.Cart, bool) {
+	var cart models.Cart
+
+	if customerID, exists := c.Get("user_id"); exists {
+		err := database.GetDB().Where("customer_id = ? AND is_active = ?", customerID, true).First(&cart).Error
+		if err == nil {
+			return cart, true
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.InternalServerErrorJSON(c, err.Error())
+			return cart, false
+		}
+		if !create {
+			utils.NotFoundRequestErrorJson(c, "There is no active cart for this customer.")
+			return cart, false
+		}
+
+		id := customerID.(uint)
+		cart = models.Cart{
+			CustomerID: &id,
+			IsActive:   true,
+		}
+		if err := database.GetDB().Create(&cart).Error; err != nil {
+			utils.InternalServerErrorJSON(c, err.Error())
+			return cart, false
+		}
+
+		return cart, true
+	}
+
+	if token := c.GetHeader(utils.CartTokenHeader); token != "" {
+		var err error
+		cart, err = services.FindGuestCart(database.GetDB(), token)
+		if err == nil {
+			return cart, true
+		}
+		if !errors.Is(err, utils.ErrInvalidCartToken) {
+			utils.InternalServerErrorJSON(c, err.Error())
+			return cart, false
+		}
+	}
+
+	if !create {
+		utils.NotFoundRequestErrorJson(c, "Cart not found")
+		return cart, false
+	}
+
+	cart, token, err := services.CreateGuestCart(database.GetDB())
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return cart, false
+	}
+
+	c.Header(utils.CartTokenHeader, token)
+	return cart, true
+}
+
+// mergeGuestCart ignores unknown or already merged X-Cart-Token values.
+func mergeGuestCart(c *gin.Context, customerID uint) bool {
+	token := c.GetHeader(utils.CartTokenHeader)
+	if token == "" {
+		return true
+	}
+
+	if _, err := services.MergeGuestCart(database.GetDB(), token, customerID, services.CartMergeStrategy()); err != nil && !errors.Is(err, utils.ErrInvalidCartToken) {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return false
+	}
+
+	return true
+}
+
+func respondWithPricedCart(c *gin.Context, cart *models.Cart, statusCode int) {
+	var options services.PricingOptions
+
+	if cart.CustomerID != nil {
+		options.CustomerID = *cart.CustomerID
+
+		var address models.Address
+		err := database.GetDB().Where("customer_id = ? AND is_default_shipping = ?", cart.CustomerID, true).First(&address).Error
+		if err == nil {
+			options.Address = &address.Fields
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.InternalServerErrorJSON(c, err.Error())
+			return
+		}
+	}
+
+	priced, err := services.RefreshCartTotal(database.GetDB(), cart, options)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, statusCode, priced)
+}
+
+func GetCartShippingQuote(c *gin.Context) {
+	customerID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	country := strings.ToUpper(c.Query("country"))
+	if country == "" {
+		var addressID *uint
+		if id, err := strconv.ParseUint(c.Query("address_id"), 10, 64); err == nil {
+			parsed := uint(id)
+			addressID = &parsed
+		}
+
+		address, ok := resolveShippingAddress(c, customerID, addressID, nil)
+		if !ok {
+			return
+		}
+		country = address.Country
+	}
+
+	var cart models.Cart
+	if err := database.GetDB().Where("customer_id = ? AND is_active = ?", customerID, true).First(&cart).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			utils.NotFoundRequestErrorJson(c, "Cart item not found")
+			utils.NotFoundRequestErrorJson(c, "There is no active cart for this customer.")
 			return
 		}
 
@@ -148,10 +300,22 @@ func DeleteCartItem(c *gin.Context) {
 		return
 	}
 
-	if err := database.GetDB().Unscoped().Delete(&cartItem).Error; err != nil {
+	cartItems, _, err := services.LoadCartItems(database.GetDB(), cart.ID)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(cartItems) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No items found for this customer's cart.")
+		return
+	}
+
+	quotes, err := services.QuoteShipping(database.GetDB(), cartItems, country)
+	if err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
-	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Cart item deleted successfully"})
+	utils.JSONResponse(c, http.StatusOK, gin.H{"country": country, "sellers": quotes})
 }
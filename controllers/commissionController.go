@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"api/audit"
+	"api/database"
+	"api/models"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func GetCommissionRules(c *gin.Context) {
+	var rules []models.CommissionRule
+	if err := database.GetDB().Find(&rules).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(rules) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No commission rules found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, rules)
+}
+
+func CreateCommissionRule(c *gin.Context) {
+	var input struct {
+		SellerID *uint   `json:"seller_id" binding:"omitempty"`
+		Category string  `json:"category" binding:"omitempty"`
+		Rate     float64 `json:"rate" binding:"gte=0,lte=1"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.SellerID != nil {
+		if err := database.GetDB().First(&models.Seller{}, *input.SellerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				utils.NotFoundRequestErrorJson(c, "seller not found")
+				return
+			}
+
+			utils.InternalServerErrorJSON(c, err.Error())
+			return
+		}
+	}
+
+	query := database.GetDB().Where("category = ?", input.Category)
+	if input.SellerID != nil {
+		query = query.Where("seller_id = ?", *input.SellerID)
+	} else {
+		query = query.Where("seller_id IS NULL")
+	}
+	if err := query.First(&models.CommissionRule{}).Error; err == nil {
+		utils.ConflictRequestErrorJson(c, "Commission rule already exists for this seller and category")
+		return
+	}
+
+	rule := models.CommissionRule{
+		SellerID: input.SellerID,
+		Category: input.Category,
+		Rate:     input.Rate,
+	}
+
+	if err := database.GetDB().Create(&rule).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, rule)
+}
+
+func UpdateCommissionRule(c *gin.Context) {
+	var rule models.CommissionRule
+	if err := database.GetDB().First(&rule, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Commission rule not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetBefore(c, rule)
+
+	var input struct {
+		Rate float64 `json:"rate" binding:"gte=0,lte=1"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	rule.Rate = input.Rate
+	if err := database.GetDB().Save(&rule).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, rule)
+	utils.JSONResponse(c, http.StatusOK, rule)
+}
+
+func DeleteCommissionRule(c *gin.Context) {
+	var rule models.CommissionRule
+	if err := database.GetDB().First(&rule, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Commission rule not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if err := database.GetDB().Unscoped().Delete(&rule).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Commission rule deleted successfully"})
+}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"api/audit"
 	"api/database"
 	"api/models"
+	"api/services"
 	"api/utils"
 	"errors"
 	"github.com/gin-gonic/gin"
@@ -54,11 +56,11 @@ func GetCustomer(c *gin.Context) {
 
 func CreateCustomer(c *gin.Context) {
 	var customerInput struct {
-		Email    string `json:"email" binding:"required,email"`
-		Name     string `json:"name" binding:"required"`
-		Phone    string `json:"phone" binding:"required"`
-		Password string `json:"password" binding:"required,min=6"`
-		Address  string `json:"address" binding:"required"`
+		Email    string               `json:"email" binding:"required,email"`
+		Name     string               `json:"name" binding:"required"`
+		Phone    string               `json:"phone" binding:"required"`
+		Password string               `json:"password" binding:"required,min=6"`
+		Address  *models.AddressInput `json:"address" binding:"omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&customerInput); err != nil {
@@ -85,6 +87,14 @@ func CreateCustomer(c *gin.Context) {
 		return
 	}
 
+	var addressFields models.AddressFields
+	if customerInput.Address != nil {
+		var ok bool
+		if addressFields, ok = customerAddressFields(c, *customerInput.Address, customerInput.Name, customerInput.Phone); !ok {
+			return
+		}
+	}
+
 	hashedPassword, err := utils.HashPassword(customerInput.Password)
 	if err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
@@ -98,14 +108,37 @@ func CreateCustomer(c *gin.Context) {
 			Password: hashedPassword,
 			Name:     customerInput.Name,
 		},
-		Address: customerInput.Address,
 	}
 
-	if err := database.GetDB().Create(&newCustomer).Error; err != nil {
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newCustomer).Error; err != nil {
+			return err
+		}
+
+		if customerInput.Address == nil {
+			return nil
+		}
+
+		address := models.Address{
+			CustomerID: newCustomer.ID,
+			Fields:     addressFields,
+		}
+		if err := saveCustomerAddress(tx, &address); err != nil {
+			return err
+		}
+
+		newCustomer.Addresses = append(newCustomer.Addresses, address)
+		return nil
+	})
+	if err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
+	if !mergeGuestCart(c, newCustomer.ID) {
+		return
+	}
+
 	utils.JSONResponse(c, http.StatusCreated, newCustomer)
 }
 
@@ -123,12 +156,14 @@ func UpdateCustomer(c *gin.Context) {
 		return
 	}
 
+	audit.SetBefore(c, existingCustomer)
+
 	var customerInput struct {
-		Email    string `json:"email" binding:"omitempty,email"`
-		Phone    string `json:"phone" binding:"omitempty"`
-		Password string `json:"password" binding:"omitempty,min=6"`
-		Name     string `json:"name" binding:"omitempty"`
-		Address  string `json:"address" binding:"omitempty"`
+		Email    string               `json:"email" binding:"omitempty,email"`
+		Phone    string               `json:"phone" binding:"omitempty"`
+		Password string               `json:"password" binding:"omitempty,min=6"`
+		Name     string               `json:"name" binding:"omitempty"`
+		Address  *models.AddressInput `json:"address" binding:"omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&customerInput); err != nil {
@@ -171,15 +206,38 @@ func UpdateCustomer(c *gin.Context) {
 	if customerInput.Name != "" {
 		existingCustomer.Name = customerInput.Name
 	}
-	if customerInput.Address != "" {
-		existingCustomer.Address = customerInput.Address
+
+	var addressFields models.AddressFields
+	if customerInput.Address != nil {
+		var ok bool
+		if addressFields, ok = customerAddressFields(c, *customerInput.Address, existingCustomer.Name, existingCustomer.Phone); !ok {
+			return
+		}
 	}
 
-	if err := database.GetDB().Save(&existingCustomer).Error; err != nil {
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&existingCustomer).Error; err != nil {
+			return err
+		}
+
+		if customerInput.Address == nil {
+			return nil
+		}
+
+		address := models.Address{CustomerID: existingCustomer.ID, IsDefaultShipping: true}
+		if err := tx.Where("customer_id = ? AND is_default_shipping = ?", existingCustomer.ID, true).First(&address).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		address.Fields = addressFields
+		return saveCustomerAddress(tx, &address)
+	})
+	if err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
+	audit.SetAfter(c, existingCustomer)
 	utils.JSONResponse(c, http.StatusOK, existingCustomer)
 }
 
@@ -197,10 +255,77 @@ func DeleteCustomer(c *gin.Context) {
 		return
 	}
 
-	if err := database.GetDB().Unscoped().Delete(&existingCustomer).Error; err != nil {
+	audit.SetBefore(c, existingCustomer)
+
+	if err := database.GetDB().Delete(&existingCustomer).Error; err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
 	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Customer deleted successfully"})
 }
+
+func GetDeletedCustomers(c *gin.Context) {
+	var customers []models.Customer
+	if err := services.OnlyDeleted(database.GetDB()).Order("deleted_at DESC").Find(&customers).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(customers) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No deleted customers found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, customers)
+}
+
+func RestoreCustomer(c *gin.Context) {
+	var customer models.Customer
+	if err := services.OnlyDeleted(database.GetDB()).First(&customer, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Deleted customer not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetBefore(c, customer)
+
+	if err := services.RestoreCustomer(database.GetDB(), &customer); err != nil {
+		if errors.Is(err, services.ErrRestoreConflict) {
+			utils.ConflictRequestErrorJson(c, "Another customer already uses this email or phone")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, customer)
+
+	utils.JSONResponse(c, http.StatusOK, customer)
+}
+
+func customerAddressFields(c *gin.Context, input models.AddressInput, name string, phone string) (models.AddressFields, bool) {
+	fields := utils.NormalizeAddress(input.AddressFields)
+	if !input.Legacy {
+		if errs := utils.ValidateAddress(fields); len(errs) > 0 {
+			utils.AddressValidationErrorJson(c, errs)
+			return fields, false
+		}
+
+		return fields, true
+	}
+
+	if fields.Line1 == "" {
+		utils.AddressValidationErrorJson(c, []utils.ValidationError{{Field: "line1", Reason: "required"}})
+		return fields, false
+	}
+
+	fields.FullName = name
+	fields.Phone = phone
+	return fields, true
+}
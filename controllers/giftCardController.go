@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"api/audit"
+	"api/database"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func GetGiftCards(c *gin.Context) {
+	query := database.GetDB()
+	switch c.Query("status") {
+	case "redeemed":
+		query = query.Where("redeemed_at IS NOT NULL")
+	case "active":
+		query = query.Where("redeemed_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", time.Now())
+	}
+
+	var giftCards []models.GiftCard
+	if err := query.Order("created_at DESC").Find(&giftCards).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(giftCards) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No gift cards found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, giftCards)
+}
+
+func GetGiftCard(c *gin.Context) {
+	var giftCard models.GiftCard
+	if err := database.GetDB().First(&giftCard, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Gift card not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, giftCard)
+}
+
+func IssueGiftCard(c *gin.Context) {
+	adminId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Admin is not authenticated")
+		return
+	}
+
+	var input struct {
+		Amount    float64    `json:"amount" binding:"required,gt=0"`
+		ExpiresAt *time.Time `json:"expires_at" binding:"omitempty"`
+		Note      string     `json:"note" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.ExpiresAt != nil && input.ExpiresAt.Before(time.Now()) {
+		utils.BadRequestErrorJson(c, "expires_at must be in the future")
+		return
+	}
+
+	giftCard, err := services.IssueGiftCard(database.GetDB(), adminId.(uint), input.Amount, input.ExpiresAt, input.Note)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetTarget(c, "gift-cards", strconv.FormatUint(uint64(giftCard.ID), 10))
+	audit.SetAfter(c, giftCard)
+	utils.JSONResponse(c, http.StatusCreated, giftCard)
+}
+
+func RedeemGiftCard(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	entry, err := services.RedeemGiftCard(database.GetDB(), customerId.(uint), input.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrGiftCardNotFound):
+			utils.NotFoundRequestErrorJson(c, err.Error())
+		case errors.Is(err, services.ErrGiftCardRedeemed):
+			utils.ConflictRequestErrorJson(c, err.Error())
+		case errors.Is(err, services.ErrGiftCardExpired):
+			utils.BadRequestErrorJson(c, err.Error())
+		default:
+			utils.InternalServerErrorJSON(c, err.Error())
+		}
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, entry)
+}
+
+func GetStoreCredit(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var customer models.Customer
+	if err := database.GetDB().First(&customer, customerId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Customer not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	var entries []models.StoreCreditEntry
+	if err := database.GetDB().Where("customer_id = ?", customerId).Order("created_at DESC").Find(&entries).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{
+		"balance": customer.StoreCreditBalance,
+		"entries": entries,
+	})
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"api/audit"
+	"api/database"
+	"api/jobs"
+	"api/models"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func GetJobs(c *gin.Context) {
+	query := database.GetDB().Order("created_at DESC").Limit(200)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if jobType := c.Query("type"); jobType != "" {
+		query = query.Where("type = ?", jobType)
+	}
+	if queue := c.Query("queue"); queue != "" {
+		query = query.Where("queue = ?", queue)
+	}
+
+	var jobList []models.Job
+	if err := query.Find(&jobList).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(jobList) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No jobs found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, jobList)
+}
+
+func GetJob(c *gin.Context) {
+	var job models.Job
+	if err := database.GetDB().First(&job, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Job not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, job)
+}
+
+func RetryJob(c *gin.Context) {
+	var existingJob models.Job
+	if err := database.GetDB().First(&existingJob, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Job not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetBefore(c, existingJob)
+
+	job, err := jobs.RetryJob(database.GetDB(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundRequestErrorJson(c, "Job not found")
+		case errors.Is(err, jobs.ErrJobNotRetryable):
+			utils.ConflictRequestErrorJson(c, "Only dead-lettered jobs can be retried")
+		default:
+			utils.InternalServerErrorJSON(c, err.Error())
+		}
+		return
+	}
+
+	audit.SetAfter(c, job)
+	utils.JSONResponse(c, http.StatusOK, job)
+}
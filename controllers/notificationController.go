@@ -0,0 +1,298 @@
+package controllers
+
+import (
+	"api/database"
+	"api/models"
+	"api/notifications"
+	"api/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func GetNotificationPreferences(c *gin.Context) {
+	userId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "User is not authenticated")
+		return
+	}
+
+	preference, err := notifications.Preferences(database.GetDB(), c.GetString("user_type"), userId.(uint))
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, preference)
+}
+
+func UpdateNotificationPreferences(c *gin.Context) {
+	userId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "User is not authenticated")
+		return
+	}
+
+	var input struct {
+		Locale       string   `json:"locale" binding:"omitempty"`
+		EmailEnabled *bool    `json:"email_enabled" binding:"omitempty"`
+		SMSEnabled   *bool    `json:"sms_enabled" binding:"omitempty"`
+		InAppEnabled *bool    `json:"in_app_enabled" binding:"omitempty"`
+		MutedKinds   []string `json:"muted_kinds" binding:"omitempty,dive,oneof=order_placed seller_new_order order_shipped order_delivered order_cancelled order_item_status order_item_cancelled cart_reminder price_drop"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.Locale != "" && !notifications.SupportsLocale(input.Locale) {
+		utils.BadRequestErrorJson(c, "locale must be one of "+strings.Join(notifications.Locales(), ", "))
+		return
+	}
+
+	preference, err := notifications.Preferences(database.GetDB(), c.GetString("user_type"), userId.(uint))
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if input.Locale != "" {
+		preference.Locale = input.Locale
+	}
+	if input.EmailEnabled != nil {
+		preference.EmailEnabled = *input.EmailEnabled
+	}
+	if input.SMSEnabled != nil {
+		preference.SMSEnabled = *input.SMSEnabled
+	}
+	if input.InAppEnabled != nil {
+		preference.InAppEnabled = *input.InAppEnabled
+	}
+	if input.MutedKinds != nil {
+		preference.MutedKinds = strings.Join(input.MutedKinds, ",")
+	}
+
+	if err := database.GetDB().Save(&preference).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, preference)
+}
+
+func GetNotifications(c *gin.Context) {
+	userId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "User is not authenticated")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 100 {
+		utils.BadRequestErrorJson(c, "limit must be between 1 and 100")
+		return
+	}
+
+	query := database.GetDB().
+		Where("user_type = ? AND user_id = ?", c.GetString("user_type"), userId).
+		Order("id DESC").
+		Limit(limit)
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+	if beforeID := c.Query("before_id"); beforeID != "" {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var inbox []models.Notification
+	if err := query.Find(&inbox).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(inbox) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No notifications found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, inbox)
+}
+
+func GetUnreadNotificationCount(c *gin.Context) {
+	userId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "User is not authenticated")
+		return
+	}
+
+	count, err := notifications.UnreadCount(database.GetDB(), c.GetString("user_type"), userId.(uint))
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"unread": count})
+}
+
+func MarkNotificationRead(c *gin.Context) {
+	userId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "User is not authenticated")
+		return
+	}
+
+	var notification models.Notification
+	if err := database.GetDB().
+		Where("user_type = ? AND user_id = ?", c.GetString("user_type"), userId).
+		First(&notification, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Notification not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		notification.ReadAt = &now
+		if err := database.GetDB().Model(&notification).Update("read_at", now).Error; err != nil {
+			utils.InternalServerErrorJSON(c, err.Error())
+			return
+		}
+	}
+
+	utils.JSONResponse(c, http.StatusOK, notification)
+}
+
+func MarkAllNotificationsRead(c *gin.Context) {
+	userId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "User is not authenticated")
+		return
+	}
+
+	result := database.GetDB().Model(&models.Notification{}).
+		Where("user_type = ? AND user_id = ? AND read_at IS NULL", c.GetString("user_type"), userId).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		utils.InternalServerErrorJSON(c, result.Error.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"updated": result.RowsAffected})
+}
+
+// StreamNotifications pushes new inbox entries as Server-Sent Events. The
+// stream polls the inbox table, so it works whichever service or worker
+// created the notification. Clients reconnecting with Last-Event-ID (or
+// ?since=) receive what they missed; otherwise the stream starts from now.
+func StreamNotifications(c *gin.Context) {
+	userId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "User is not authenticated")
+		return
+	}
+	userType := c.GetString("user_type")
+	db := database.GetDB().WithContext(c.Request.Context())
+
+	lastID, err := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+	if err != nil {
+		lastID, err = strconv.ParseUint(c.Query("since"), 10, 64)
+	}
+	if err != nil {
+		if err := db.Model(&models.Notification{}).
+			Where("user_type = ? AND user_id = ?", userType, userId).
+			Select("COALESCE(MAX(id), 0)").
+			Scan(&lastID).Error; err != nil {
+			utils.InternalServerErrorJSON(c, err.Error())
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	writeUnreadCount := func() bool {
+		count, err := notifications.UnreadCount(db, userType, userId.(uint))
+		if err != nil {
+			return false
+		}
+
+		fmt.Fprintf(c.Writer, "event: unread_count\ndata: {\"unread\":%d}\n\n", count)
+		c.Writer.Flush()
+		return true
+	}
+
+	if !writeUnreadCount() {
+		return
+	}
+
+	poll := time.NewTicker(notificationStreamPollInterval())
+	defer poll.Stop()
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		case <-poll.C:
+			var inbox []models.Notification
+			if err := db.Where("user_type = ? AND user_id = ? AND id > ?", userType, userId, lastID).Order("id").Limit(100).Find(&inbox).Error; err != nil {
+				return
+			}
+
+			if len(inbox) == 0 {
+				continue
+			}
+
+			for _, notification := range inbox {
+				data, err := json.Marshal(notification)
+				if err != nil {
+					return
+				}
+
+				fmt.Fprintf(c.Writer, "id: %d\nevent: notification\ndata: %s\n\n", notification.ID, data)
+				lastID = uint64(notification.ID)
+			}
+
+			if !writeUnreadCount() {
+				return
+			}
+		}
+	}
+}
+
+// notificationStreamPollInterval reads NOTIFICATION_STREAM_POLL_INTERVAL,
+// defaulting to 2s.
+func notificationStreamPollInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("NOTIFICATION_STREAM_POLL_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return 2 * time.Second
+	}
+
+	return interval
+}
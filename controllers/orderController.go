@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	"api/audit"
 	"api/database"
+	"api/events"
 	"api/models"
+	"api/services"
 	"api/utils"
 	"errors"
 	"github.com/gin-gonic/gin"
@@ -93,7 +96,7 @@ func GetCustomerOrder(c *gin.Context) {
 	}
 
 	var order models.Order
-	if err := database.GetDB().First(&order, c.Param("id")).Error; err != nil {
+	if err := preloadOrderDetails(database.GetDB()).First(&order, c.Param("id")).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.NotFoundRequestErrorJson(c, "Order not found")
 			return
@@ -114,7 +117,7 @@ func GetCustomerOrder(c *gin.Context) {
 		return
 	}
 
-	if cart.CustomerID != customerId {
+	if cart.CustomerID == nil || *cart.CustomerID != customerId.(uint) {
 		utils.NotFoundRequestErrorJson(c, "Order doesn't belong to this customer")
 		return
 	}
@@ -165,98 +168,167 @@ func PlaceOrder(c *gin.Context) {
 	}
 
 	var input struct {
-		Address string `json:"address" binding:"required"`
-	}
+		AddressID        *uint                 `json:"address_id" binding:"omitempty"`
+		ShippingAddress  *models.AddressFields `json:"shipping_address" binding:"omitempty"`
+		Address          *models.AddressInput  `json:"address" binding:"omitempty"`
+		BillingAddressID *uint                 `json:"billing_address_id" binding:"omitempty"`
+		UseStoreCredit   bool                  `json:"use_store_credit"`
+		ShippingMethods  []struct {
+			SellerID uint `json:"seller_id" binding:"required"`
+			RateID   uint `json:"rate_id" binding:"required"`
+		} `json:"shipping_methods" binding:"omitempty,dive"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			var verr validator.ValidationErrors
+			if errors.As(err, &verr) {
+				utils.ValidationErrorJson(c, verr)
+				return
+			}
 
-	if err := c.ShouldBindJSON(&input); err != nil {
-		var verr validator.ValidationErrors
-		if errors.As(err, &verr) {
-			utils.ValidationErrorJson(c, verr)
+			utils.BadRequestErrorJson(c, err.Error())
 			return
 		}
-
-		utils.BadRequestErrorJson(c, err.Error())
-		return
 	}
 
-	var cart models.Cart
-	if err := database.GetDB().Where("customer_id = ? AND is_active = ?", customerId, true).First(&cart).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			utils.NotFoundRequestErrorJson(c, "There is no active cart for this customer.")
+	if input.Address != nil {
+		if input.Address.Legacy {
+			utils.BadRequestErrorJson(c, "A single-line address can't be shipped to, send shipping_address or address_id instead")
 			return
 		}
-		utils.InternalServerErrorJSON(c, err.Error())
-		return
+		if input.ShippingAddress == nil {
+			input.ShippingAddress = &input.Address.AddressFields
+		}
 	}
 
-	if err := database.GetDB().Where("cart_id = ?", cart.ID).First(&models.Order{}).Error; err == nil {
-		utils.ConflictRequestErrorJson(c, "There is an already placed order for this customer's cart")
+	shippingAddress, ok := resolveShippingAddress(c, customerId, input.AddressID, input.ShippingAddress)
+	if !ok {
 		return
 	}
 
-	var cartItems []models.CartItem
-	if err := database.GetDB().Where("cart_id = ?", cart.ID).Find(&cartItems).Error; err != nil {
-		utils.InternalServerErrorJSON(c, err.Error())
+	billingAddress, ok := resolveBillingAddress(c, customerId, input.BillingAddressID, shippingAddress)
+	if !ok {
 		return
 	}
 
-	if len(cartItems) == 0 {
-		utils.NotFoundRequestErrorJson(c, "No items found for this customer's cart.")
-		return
+	chosenMethods := map[uint]uint{}
+	for _, method := range input.ShippingMethods {
+		chosenMethods[method.SellerID] = method.RateID
 	}
 
-	totalAmount := 0.0
-	for _, item := range cartItems {
-		var product models.Product
-		if err := database.GetDB().First(&product, item.ProductID).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				utils.NotFoundRequestErrorJson(c, "One of products in cart is not found")
-				return
-			}
+	var order models.Order
+	var priced services.PricedCart
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		cart, err := services.LockActiveCart(tx, customerId.(uint))
+		if err != nil {
+			return err
+		}
 
-			utils.InternalServerErrorJSON(c, err.Error())
-			return
+		priced, err = services.PriceCart(tx, cart, services.PricingOptions{
+			CustomerID:      customerId.(uint),
+			Address:         &shippingAddress,
+			ShippingMethods: chosenMethods,
+			RequireShipping: true,
+		})
+		if err != nil {
+			return err
 		}
-		totalAmount += float64(item.Quantity) * product.Price
-	}
 
-	order := models.Order{
-		CartID:      cart.ID,
-		TotalAmount: totalAmount,
-		OrderedDate: time.Now(),
-		Status:      utils.StatusPending,
-	}
+		order = models.Order{
+			CartID:         cart.ID,
+			Subtotal:       priced.Subtotal,
+			ShippingCost:   priced.ShippingCost,
+			DiscountAmount: priced.DiscountAmount,
+			TaxAmount:      priced.TaxAmount,
+			TotalAmount:    priced.Total,
+			OrderedDate:    time.Now(),
+			Status:         utils.StatusPending,
+			BillingAddress: billingAddress,
+		}
 
-	if err := database.GetDB().Create(&order).Error; err != nil {
-		utils.InternalServerErrorJSON(c, err.Error())
-		return
-	}
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
 
-	ShippingInfo := models.ShippingInfo{
-		OrderID: order.ID,
-		Address: input.Address,
-	}
+		subOrders, err := services.SplitOrder(tx, order, priced.Items, shippingAddress, priced.Shipping, priced.ShippingDiscounts)
+		if err != nil {
+			return err
+		}
 
-	if err := database.GetDB().Create(&ShippingInfo).Error; err != nil {
-		utils.InternalServerErrorJSON(c, err.Error())
-		return
-	}
+		if err := services.RedeemPromotions(tx, order, customerId.(uint), priced.Discounts); err != nil {
+			return err
+		}
 
-	payment := models.Payment{
-		OrderID:     order.ID,
-		TotalAmount: totalAmount,
-		Paid:        false,
-	}
+		if err := services.SettleOrder(tx, order, subOrders, priced.Discounts); err != nil {
+			return err
+		}
 
-	if err := database.GetDB().Create(&payment).Error; err != nil {
-		utils.InternalServerErrorJSON(c, err.Error())
+		shippingInfo := models.ShippingInfo{
+			OrderID:         order.ID,
+			Address:         utils.FormatAddress(shippingAddress),
+			ShippingAddress: shippingAddress,
+		}
+
+		if err := tx.Create(&shippingInfo).Error; err != nil {
+			return err
+		}
+
+		payment := models.Payment{
+			OrderID:     order.ID,
+			TotalAmount: order.TotalAmount,
+			Paid:        false,
+			Status:      utils.PaymentStatusUnpaid,
+		}
+
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+
+		if input.UseStoreCredit {
+			applied, err := services.SpendStoreCredit(tx, customerId.(uint), order.TotalAmount, order.ID)
+			if err != nil {
+				return err
+			}
+
+			if applied > 0 {
+				if _, err := services.RecordPaymentTransaction(tx, order.ID, utils.PaymentTransactionAuthorization, utils.PaymentMethodStoreCredit, applied, "store credit"); err != nil {
+					return err
+				}
+				if _, err := services.RecordPaymentTransaction(tx, order.ID, utils.PaymentTransactionCapture, utils.PaymentMethodStoreCredit, applied, "store credit"); err != nil {
+					return err
+				}
+			}
+		}
+
+		placed, sellerIDs := events.NewOrderPlaced(order, customerId.(uint), subOrders, priced.Items)
+		if err := events.Record(tx, "order", order.ID, placed, sellerIDs...); err != nil {
+			return err
+		}
+
+		return tx.Model(&cart).Updates(map[string]interface{}{"is_active": false, "total_price": order.TotalAmount}).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNoActiveCart):
+			utils.NotFoundRequestErrorJson(c, "There is no active cart for this customer.")
+		case errors.Is(err, services.ErrCartAlreadyOrdered):
+			utils.ConflictRequestErrorJson(c, err.Error())
+		case errors.Is(err, services.ErrEmptyCart):
+			utils.NotFoundRequestErrorJson(c, "No items found for this customer's cart.")
+		case errors.Is(err, services.ErrUnavailableCartItems):
+			utils.ErrorJSON(c, http.StatusConflict, gin.H{"message": err.Error(), "unavailable_items": priced.UnavailableItems})
+		case errors.Is(err, services.ErrCouponNotFound), errors.Is(err, services.ErrCouponUnavailable), errors.Is(err, services.ErrPromotionLimitsUsed):
+			utils.ConflictRequestErrorJson(c, err.Error())
+		case errors.Is(err, services.ErrNoShippingOption), errors.Is(err, services.ErrInvalidShippingMethod):
+			utils.BadRequestErrorJson(c, err.Error())
+		default:
+			utils.InternalServerErrorJSON(c, err.Error())
+		}
 		return
 	}
 
-	cart.IsActive = false
-	database.GetDB().Save(&cart)
-
-	if err := database.GetDB().Preload("Payment").Preload("ShippingInfo").First(&order, order.ID).Error; err != nil {
+	if err := preloadOrderDetails(database.GetDB()).First(&order, order.ID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.NotFoundRequestErrorJson(c, "Order not found")
 			return
@@ -304,7 +376,7 @@ func UpdateOrderItemStatus(c *gin.Context) {
 	}
 
 	var product models.Product
-	if err := database.GetDB().First(&product, cartItem.ProductID).Error; err != nil {
+	if err := database.GetDB().Unscoped().First(&product, cartItem.ProductID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.NotFoundRequestErrorJson(c, "Product from order item is not found.")
 			return
@@ -334,6 +406,8 @@ func UpdateOrderItemStatus(c *gin.Context) {
 		return
 	}
 
+	previousStatus := cartItem.Status
+	audit.SetBefore(c, cartItem)
 	switch input.Status {
 	case utils.StatusPending, utils.StatusShipped, utils.StatusDelivered, utils.StatusCancelled:
 		cartItem.Status = input.Status
@@ -342,10 +416,28 @@ func UpdateOrderItemStatus(c *gin.Context) {
 		return
 	}
 
-	if err := database.GetDB().Save(&cartItem).Error; err != nil {
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&cartItem).Error; err != nil {
+			return err
+		}
+
+		cartItem.Product = &product
+		if err := events.RecordOrderItemStatusChange(tx, existingOrder.ID, cartItem, previousStatus); err != nil {
+			return err
+		}
+
+		if cartItem.SubOrderID == nil {
+			return nil
+		}
+
+		return services.RefreshSubOrderStatus(tx, *cartItem.SubOrderID)
+	})
+	if err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
+		return
 	}
 
+	audit.SetAfter(c, cartItem)
 	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Order item status successfully updated."})
 }
 
@@ -361,10 +453,55 @@ func DeleteOrder(c *gin.Context) {
 		return
 	}
 
-	if err := database.GetDB().Unscoped().Delete(&existingOrder).Error; err != nil {
+	audit.SetBefore(c, existingOrder)
+
+	if err := database.GetDB().Delete(&existingOrder).Error; err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
 	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Order deleted successfully"})
 }
+
+func GetDeletedOrders(c *gin.Context) {
+	var orders []models.Order
+	if err := services.OnlyDeleted(database.GetDB()).Order("deleted_at DESC").Find(&orders).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(orders) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No deleted orders found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, orders)
+}
+
+func RestoreOrder(c *gin.Context) {
+	var order models.Order
+	if err := services.OnlyDeleted(database.GetDB()).First(&order, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Deleted order not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetBefore(c, order)
+
+	if err := services.RestoreOrder(database.GetDB(), &order); err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, order)
+
+	utils.JSONResponse(c, http.StatusOK, order)
+}
+
+func preloadOrderDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Payment").Preload("Discounts").Preload("ShippingInfo", "sub_order_id IS NULL").Preload("SubOrders.ShippingInfo").Preload("SubOrders.CartItems.Product", services.IncludeDeleted)
+}
@@ -1,56 +1,128 @@
 package controllers
 
 import (
+	"api/audit"
 	"api/database"
 	"api/models"
+	"api/services"
 	"api/utils"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
 	"net/http"
+	"strconv"
 )
 
 func GetOrderPayment(c *gin.Context) {
-	orderID := c.Param("id")
-	var payment models.Payment
-	if err := database.GetDB().Where("order_id = ?", orderID).First(&payment).Error; err != nil {
-		utils.ErrorJSON(c, http.StatusNotFound, gin.H{
-			"error":   err.Error(),
-			"message": "Payment not found",
-		})
+	payment, err := services.LoadPayment(database.GetDB(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Payment not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
 	c.JSON(http.StatusOK, payment)
 }
 
-func UpdatePaymentStatus(c *gin.Context) {
-	orderID := c.Param("id")
-	var payment models.Payment
-	if err := database.GetDB().Where("order_id = ?", orderID).First(&payment).Error; err != nil {
-		utils.ErrorJSON(c, http.StatusNotFound, gin.H{
-			"error":   err.Error(),
-			"message": "OrderPayment not found",
-		})
+func GetOrderPaymentTransactions(c *gin.Context) {
+	payment, err := services.LoadPayment(database.GetDB(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Payment not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
+	c.JSON(http.StatusOK, payment.Transactions)
+}
+
+func AuthorizePayment(c *gin.Context) {
+	recordPaymentTransaction(c, utils.PaymentTransactionAuthorization)
+}
+
+func CapturePayment(c *gin.Context) {
+	recordPaymentTransaction(c, utils.PaymentTransactionCapture)
+}
+
+func RefundPayment(c *gin.Context) {
+	recordPaymentTransaction(c, utils.PaymentTransactionRefund)
+}
+
+func ChargebackPayment(c *gin.Context) {
+	recordPaymentTransaction(c, utils.PaymentTransactionChargeback)
+}
+
+func recordPaymentTransaction(c *gin.Context, transactionType string) {
 	var input struct {
-		Paid bool `json:"paid" binding:"required"`
+		Method        string  `json:"method" binding:"required"`
+		Amount        float64 `json:"amount" binding:"required,gt=0"`
+		Reference     string  `json:"reference" binding:"omitempty"`
+		ToStoreCredit bool    `json:"to_store_credit"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorJSON(c, http.StatusUnprocessableEntity, gin.H{
-			"error":   err.Error(),
-			"message": "Invalid input",
-		})
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
 		return
 	}
 
-	payment.Paid = input.Paid
+	toStoreCredit := transactionType == utils.PaymentTransactionRefund && (input.ToStoreCredit || input.Method == utils.PaymentMethodStoreCredit)
 
-	if err := database.GetDB().Save(&payment).Error; err != nil {
-		utils.InternalServerErrorJSON(c, err.Error())
+	var payment models.Payment
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		payment, err = services.RecordPaymentTransaction(tx, c.Param("id"), transactionType, input.Method, input.Amount, input.Reference)
+		if err != nil || !toStoreCredit {
+			return err
+		}
+
+		var cart models.Cart
+		if err := tx.Where("id = (SELECT cart_id FROM orders WHERE id = ?)", payment.OrderID).First(&cart).Error; err != nil {
+			return err
+		}
+
+		if cart.CustomerID == nil {
+			return services.ErrGuestCart
+		}
+
+		_, err = services.CreditStoreCredit(tx, *cart.CustomerID, input.Amount, utils.StoreCreditRefund, &payment.OrderID, fmt.Sprintf("Refund for order #%d", payment.OrderID))
+		return err
+	})
+	if err != nil {
+		handlePaymentError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
+	audit.SetTarget(c, "payments", strconv.FormatUint(uint64(payment.ID), 10))
+	audit.SetAfter(c, payment)
+	utils.JSONResponse(c, http.StatusCreated, payment)
+}
+
+func handlePaymentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundRequestErrorJson(c, "Payment not found")
+	case errors.Is(err, services.ErrInvalidPaymentAmount),
+		errors.Is(err, services.ErrInvalidTransactionType),
+		errors.Is(err, services.ErrAuthorizationExceedsTotal),
+		errors.Is(err, services.ErrCaptureExceedsAuthorized),
+		errors.Is(err, services.ErrRefundExceedsCaptured):
+		utils.BadRequestErrorJson(c, err.Error())
+	default:
+		utils.InternalServerErrorJSON(c, err.Error())
+	}
 }
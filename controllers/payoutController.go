@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"api/audit"
+	"api/database"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func GetSellerBalance(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	balance, err := services.SellerBalance(database.GetDB(), sellerId)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	var pending float64
+	if err := database.GetDB().Model(&models.Payout{}).Where("seller_id = ? AND status = ?", sellerId, utils.PayoutStatusPending).Select("COALESCE(SUM(amount), 0)").Scan(&pending).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{
+		"balance":        balance,
+		"pending_payout": utils.RoundMoney(pending),
+	})
+}
+
+func GetSellerLedger(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var entries []models.SellerLedgerEntry
+	if err := database.GetDB().Where("seller_id = ?", sellerId).Order("created_at DESC").Find(&entries).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(entries) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No ledger entries found for this seller")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, entries)
+}
+
+func GetSellerPayouts(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var payouts []models.Payout
+	if err := database.GetDB().Where("seller_id = ?", sellerId).Order("created_at DESC").Find(&payouts).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(payouts) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No payouts found for this seller")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, payouts)
+}
+
+func GetPayoutBatches(c *gin.Context) {
+	var batches []models.PayoutBatch
+	if err := database.GetDB().Order("period_end DESC").Find(&batches).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(batches) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No payout batches found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, batches)
+}
+
+func GetPayoutBatch(c *gin.Context) {
+	var batch models.PayoutBatch
+	if err := database.GetDB().Preload("Payouts").First(&batch, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Payout batch not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, batch)
+}
+
+func CreatePayoutBatch(c *gin.Context) {
+	var input struct {
+		PeriodEnd *time.Time `json:"period_end" binding:"omitempty"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			var verr validator.ValidationErrors
+			if errors.As(err, &verr) {
+				utils.ValidationErrorJson(c, verr)
+				return
+			}
+
+			utils.BadRequestErrorJson(c, err.Error())
+			return
+		}
+	}
+
+	periodEnd := time.Now()
+	if input.PeriodEnd != nil {
+		periodEnd = *input.PeriodEnd
+	}
+
+	batch, err := services.CreatePayoutBatch(database.GetDB(), periodEnd)
+	if err != nil {
+		if errors.Is(err, services.ErrNothingToPayout) {
+			utils.BadRequestErrorJson(c, err.Error())
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetTarget(c, "payouts", strconv.FormatUint(uint64(batch.ID), 10))
+	audit.SetAfter(c, batch)
+	utils.JSONResponse(c, http.StatusCreated, batch)
+}
+
+func CompletePayoutBatch(c *gin.Context) {
+	var input struct {
+		Reference string `json:"reference" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	var existingBatch models.PayoutBatch
+	if err := database.GetDB().Preload("Payouts").First(&existingBatch, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Payout batch not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetBefore(c, existingBatch)
+
+	batch, err := services.CompletePayoutBatch(database.GetDB(), existingBatch.ID, input.Reference)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Payout batch not found")
+			return
+		}
+
+		if errors.Is(err, services.ErrPayoutBatchNotPending) {
+			utils.ConflictRequestErrorJson(c, err.Error())
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, batch)
+	utils.JSONResponse(c, http.StatusOK, batch)
+}
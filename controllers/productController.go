@@ -1,19 +1,23 @@
 package controllers
 
 import (
+	"api/audit"
 	"api/database"
+	"api/events"
 	"api/models"
+	"api/services"
 	"api/utils"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
+	"log"
 	"net/http"
 )
 
 func GetProducts(c *gin.Context) {
 	var products []models.Product
-	if err := database.GetDB().Find(&products).Error; err != nil {
+	if err := catalogProducts(c).Find(&products).Error; err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
@@ -49,7 +53,7 @@ func GetSellerProducts(c *gin.Context) {
 
 func GetProduct(c *gin.Context) {
 	var product models.Product
-	if err := database.GetDB().First(&product, c.Param("id")).Error; err != nil {
+	if err := catalogProducts(c).First(&product, c.Param("id")).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.NotFoundRequestErrorJson(c, "Product not found")
 			return
@@ -93,7 +97,14 @@ func CreateProduct(c *gin.Context) {
 		Name        string  `json:"name" binding:"required"`
 		SKU         string  `json:"sku" binding:"required"`
 		Description string  `json:"description" binding:"required"`
+		Category    string  `json:"category" binding:"omitempty"`
 		Price       float64 `json:"price" binding:"required"`
+		TaxClass    string  `json:"tax_class" binding:"omitempty"`
+		WeightKg    float64 `json:"weight_kg" binding:"omitempty,gte=0"`
+		LengthCm    float64 `json:"length_cm" binding:"omitempty,gte=0"`
+		WidthCm     float64 `json:"width_cm" binding:"omitempty,gte=0"`
+		HeightCm    float64 `json:"height_cm" binding:"omitempty,gte=0"`
+		Draft       bool    `json:"draft"`
 	}
 
 	if err := c.ShouldBindJSON(&productInput); err != nil {
@@ -120,15 +131,39 @@ func CreateProduct(c *gin.Context) {
 		return
 	}
 
+	if productInput.TaxClass == "" {
+		productInput.TaxClass = utils.DefaultTaxClass
+	}
+
 	newProduct := models.Product{
 		Name:        productInput.Name,
 		SKU:         productInput.SKU,
 		Description: productInput.Description,
+		Category:    productInput.Category,
 		Price:       productInput.Price,
+		TaxClass:    productInput.TaxClass,
+		WeightKg:    productInput.WeightKg,
+		LengthCm:    productInput.LengthCm,
+		WidthCm:     productInput.WidthCm,
+		HeightCm:    productInput.HeightCm,
 		SellerId:    sellerID.(uint),
+		Status:      utils.ProductStatusDraft,
 	}
 
-	if err := database.GetDB().Create(&newProduct).Error; err != nil {
+	if !productInput.Draft {
+		if err := services.SubmitProductForReview(database.GetDB(), &newProduct); err != nil {
+			utils.InternalServerErrorJSON(c, err.Error())
+			return
+		}
+	}
+
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newProduct).Error; err != nil {
+			return err
+		}
+
+		return events.Record(tx, "product", newProduct.ID, events.ProductCreatedV1{Product: events.NewProduct(newProduct)}, newProduct.SellerId)
+	}); err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
@@ -137,24 +172,24 @@ func CreateProduct(c *gin.Context) {
 }
 
 func UpdateProduct(c *gin.Context) {
-	productID := c.Param("id")
-
-	var existingProduct models.Product
-	if err := database.GetDB().First(&existingProduct, productID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			utils.NotFoundRequestErrorJson(c, "Product not found")
-			return
-		}
-
-		utils.InternalServerErrorJSON(c, err.Error())
+	existingProduct, ok := findSellerProduct(c)
+	if !ok {
 		return
 	}
 
+	audit.SetBefore(c, existingProduct)
+
 	var productInput struct {
 		SKU         string  `json:"sku" binding:"required"`
 		Name        string  `json:"name" binding:"omitempty"`
 		Description string  `json:"description" binding:"omitempty"`
+		Category    string  `json:"category" binding:"omitempty"`
 		Price       float64 `json:"price" binding:"omitempty"`
+		TaxClass    string  `json:"tax_class" binding:"omitempty"`
+		WeightKg    float64 `json:"weight_kg" binding:"omitempty,gte=0"`
+		LengthCm    float64 `json:"length_cm" binding:"omitempty,gte=0"`
+		WidthCm     float64 `json:"width_cm" binding:"omitempty,gte=0"`
+		HeightCm    float64 `json:"height_cm" binding:"omitempty,gte=0"`
 	}
 
 	if err := c.ShouldBindJSON(&productInput); err != nil {
@@ -183,35 +218,287 @@ func UpdateProduct(c *gin.Context) {
 	if productInput.Description != "" {
 		existingProduct.Description = productInput.Description
 	}
+	if productInput.Category != "" {
+		existingProduct.Category = productInput.Category
+	}
+	oldPrice := existingProduct.Price
 	if productInput.Price != 0 {
 		existingProduct.Price = productInput.Price
 	}
+	if productInput.TaxClass != "" {
+		existingProduct.TaxClass = productInput.TaxClass
+	}
+	if productInput.WeightKg != 0 {
+		existingProduct.WeightKg = productInput.WeightKg
+	}
+	if productInput.LengthCm != 0 {
+		existingProduct.LengthCm = productInput.LengthCm
+	}
+	if productInput.WidthCm != 0 {
+		existingProduct.WidthCm = productInput.WidthCm
+	}
+	if productInput.HeightCm != 0 {
+		existingProduct.HeightCm = productInput.HeightCm
+	}
 
-	if err := database.GetDB().Save(&existingProduct).Error; err != nil {
+	if err := services.RecheckPublishedProduct(database.GetDB(), &existingProduct); err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("average_rating", "review_count").Save(&existingProduct).Error; err != nil {
+			return err
+		}
+
+		updated := events.ProductUpdatedV1{Product: events.NewProduct(existingProduct), PreviousPrice: oldPrice}
+		return events.Record(tx, "product", existingProduct.ID, updated, existingProduct.SellerId)
+	}); err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if err := services.NotifyPriceDrop(database.GetDB(), existingProduct, oldPrice); err != nil {
+		log.Printf("Could not notify wishlist price drop for product %d: %v", existingProduct.ID, err)
+	}
+
+	audit.SetAfter(c, existingProduct)
 	utils.JSONResponse(c, http.StatusOK, existingProduct)
 }
 
 func DeleteProduct(c *gin.Context) {
-	productID := c.Param("id")
+	existingProduct, ok := findSellerProduct(c)
+	if !ok {
+		return
+	}
+
+	audit.SetBefore(c, existingProduct)
+
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&existingProduct).Error; err != nil {
+			return err
+		}
+
+		deleted := events.ProductDeletedV1{Product: events.NewProduct(existingProduct)}
+		return events.Record(tx, "product", existingProduct.ID, deleted, existingProduct.SellerId)
+	}); err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
 
-	var existingProduct models.Product
-	if err := database.GetDB().First(&existingProduct, productID).Error; err != nil {
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Product deleted successfully"})
+}
+
+func GetDeletedProducts(c *gin.Context) {
+	var products []models.Product
+	if err := services.OnlyDeleted(database.GetDB()).Order("deleted_at DESC").Find(&products).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(products) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No deleted products found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, products)
+}
+
+func RestoreProduct(c *gin.Context) {
+	var product models.Product
+	if err := services.OnlyDeleted(database.GetDB()).First(&product, c.Param("id")).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			utils.NotFoundRequestErrorJson(c, "Product not found")
+			utils.NotFoundRequestErrorJson(c, "Deleted product not found")
+			return
 		}
 
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
-	if err := database.GetDB().Unscoped().Delete(&existingProduct).Error; err != nil {
+	audit.SetBefore(c, product)
+
+	if err := services.RestoreProduct(database.GetDB(), &product); err != nil {
+		if errors.Is(err, services.ErrSellerDeleted) {
+			utils.ConflictRequestErrorJson(c, "Restore the seller before restoring their products")
+			return
+		}
+
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
-	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Product deleted successfully"})
+	audit.SetAfter(c, product)
+
+	utils.JSONResponse(c, http.StatusOK, product)
+}
+
+func SubmitProduct(c *gin.Context) {
+	product, ok := findSellerProduct(c)
+	if !ok {
+		return
+	}
+
+	if err := services.SubmitProductForReview(database.GetDB(), &product); err != nil {
+		if errors.Is(err, services.ErrInvalidProductTransition) {
+			utils.ConflictRequestErrorJson(c, "Only draft, rejected or archived products can be submitted for review")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if err := database.GetDB().Model(&product).Select("status", "moderation_flags", "moderation_reason", "submitted_at").Updates(&product).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, product)
+}
+
+func ArchiveProduct(c *gin.Context) {
+	product, ok := findSellerProduct(c)
+	if !ok {
+		return
+	}
+
+	audit.SetBefore(c, product)
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		return services.ArchiveProduct(tx, &product)
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidProductTransition) {
+			utils.ConflictRequestErrorJson(c, "Only published or rejected products can be archived")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, product)
+	utils.JSONResponse(c, http.StatusOK, product)
+}
+
+func GetProductModerationQueue(c *gin.Context) {
+	var products []models.Product
+	if err := database.GetDB().
+		Where("status = ?", utils.ProductStatusPendingReview).
+		Order("moderation_flags = '' ASC, submitted_at ASC").
+		Find(&products).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(products) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No products are waiting for review")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, products)
+}
+
+func ApproveProduct(c *gin.Context) {
+	reviewProduct(c, true)
+}
+
+func RejectProduct(c *gin.Context) {
+	reviewProduct(c, false)
+}
+
+func reviewProduct(c *gin.Context, approve bool) {
+	adminId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Admin is not authenticated")
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason" binding:"omitempty"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			var verr validator.ValidationErrors
+			if errors.As(err, &verr) {
+				utils.ValidationErrorJson(c, verr)
+				return
+			}
+
+			utils.BadRequestErrorJson(c, err.Error())
+			return
+		}
+	}
+
+	if !approve && input.Reason == "" {
+		utils.BadRequestErrorJson(c, "A rejection reason is required")
+		return
+	}
+
+	var product models.Product
+	if err := database.GetDB().First(&product, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Product not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetBefore(c, product)
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		product, err = services.ReviewProduct(tx, c.Param("id"), adminId.(uint), approve, input.Reason)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundRequestErrorJson(c, "Product not found")
+		case errors.Is(err, services.ErrInvalidProductTransition):
+			utils.ConflictRequestErrorJson(c, "Product is not waiting for review")
+		default:
+			utils.InternalServerErrorJSON(c, err.Error())
+		}
+		return
+	}
+
+	audit.SetAfter(c, product)
+	utils.JSONResponse(c, http.StatusOK, product)
+}
+
+func findSellerProduct(c *gin.Context) (models.Product, bool) {
+	var product models.Product
+
+	sellerID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return product, false
+	}
+
+	if err := database.GetDB().Where("seller_id = ?", sellerID).First(&product, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Product not found")
+			return product, false
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return product, false
+	}
+
+	return product, true
+}
+
+// catalogProducts returns the product query for catalog endpoints. Admins
+// see every product, everyone else only what the public catalog shows.
+func catalogProducts(c *gin.Context) *gorm.DB {
+	if c.GetString("user_type") == "admin" {
+		return database.GetDB()
+	}
+
+	return services.VisibleProducts(database.GetDB())
 }
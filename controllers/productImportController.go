@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"api/database"
+	"api/jobs"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const maxProductImportSize = 20 << 20
+
+func CreateProductImport(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequestErrorJson(c, "file is required")
+		return
+	}
+
+	if fileHeader.Size > maxProductImportSize {
+		utils.BadRequestErrorJson(c, "Import files must be 20MB or smaller")
+		return
+	}
+
+	format := strings.ToLower(c.PostForm("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	if format != utils.FileFormatCSV && format != utils.FileFormatJSONL {
+		utils.BadRequestErrorJson(c, "format must be csv or jsonl")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+	defer file.Close()
+
+	name := fmt.Sprintf("imports/%d/%d-%s", sellerId, time.Now().UnixNano(), filepath.Base(fileHeader.Filename))
+	path, err := services.GetDocumentStorage().Save(name, file)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	productImport := models.ProductImport{
+		SellerID:    sellerId.(uint),
+		Format:      format,
+		FileName:    filepath.Base(fileHeader.Filename),
+		StoragePath: path,
+		Status:      utils.ImportStatusPending,
+	}
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&productImport).Error; err != nil {
+			return err
+		}
+
+		_, err := jobs.Enqueue(tx, jobs.TypeProductImport, jobs.ProductImportPayload{ImportID: productImport.ID}, jobs.EnqueueOptions{})
+		return err
+	}); err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusAccepted, productImport)
+}
+
+func GetProductImports(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var imports []models.ProductImport
+	if err := database.GetDB().Where("seller_id = ?", sellerId).Order("created_at DESC").Find(&imports).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(imports) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No product imports found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, imports)
+}
+
+func GetProductImport(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var productImport models.ProductImport
+	if err := database.GetDB().
+		Preload("Errors", func(db *gorm.DB) *gorm.DB { return db.Order("row") }).
+		Where("seller_id = ?", sellerId).
+		First(&productImport, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Product import not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, productImport)
+}
+
+func ExportProducts(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", utils.FileFormatCSV))
+	if format != utils.FileFormatCSV && format != utils.FileFormatJSONL {
+		utils.BadRequestErrorJson(c, "format must be csv or jsonl")
+		return
+	}
+
+	var products []models.Product
+	if err := database.GetDB().Where("seller_id = ?", sellerId).Order("sku").Find(&products).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("products-%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if format == utils.FileFormatJSONL {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+
+		encoder := json.NewEncoder(c.Writer)
+		for _, product := range products {
+			if err := encoder.Encode(services.ProductRowFromModel(product)); err != nil {
+				log.Printf("Could not write product export: %v", err)
+				return
+			}
+		}
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.Write(services.ProductExportColumns); err != nil {
+		log.Printf("Could not write product export: %v", err)
+		return
+	}
+	for _, product := range products {
+		if err := writer.Write(services.ProductRowFromModel(product).CSVRecord()); err != nil {
+			log.Printf("Could not write product export: %v", err)
+			return
+		}
+	}
+	writer.Flush()
+}
@@ -0,0 +1,419 @@
+package controllers
+
+import (
+	"api/audit"
+	"api/database"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func GetPromotions(c *gin.Context) {
+	var promotions []models.Promotion
+	if err := promotionScope(c).Preload("Coupons").Order("created_at DESC").Find(&promotions).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(promotions) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No promotions found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, promotions)
+}
+
+func GetPromotion(c *gin.Context) {
+	promotion, ok := findPromotion(c)
+	if !ok {
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, promotion)
+}
+
+func CreatePromotion(c *gin.Context) {
+	var input struct {
+		Name             string     `json:"name" binding:"required"`
+		Description      string     `json:"description" binding:"omitempty"`
+		SellerID         *uint      `json:"seller_id" binding:"omitempty"`
+		Type             string     `json:"type" binding:"required,oneof=percentage fixed buy_x_get_y free_shipping"`
+		Value            float64    `json:"value" binding:"gte=0"`
+		ProductID        *uint      `json:"product_id" binding:"omitempty"`
+		Category         string     `json:"category" binding:"omitempty"`
+		BuyQuantity      int        `json:"buy_quantity" binding:"gte=0"`
+		GetQuantity      int        `json:"get_quantity" binding:"gte=0"`
+		MinSubtotal      float64    `json:"min_subtotal" binding:"gte=0"`
+		StartsAt         *time.Time `json:"starts_at" binding:"omitempty"`
+		EndsAt           *time.Time `json:"ends_at" binding:"omitempty"`
+		UsageLimit       int        `json:"usage_limit" binding:"gte=0"`
+		PerCustomerLimit int        `json:"per_customer_limit" binding:"gte=0"`
+		RequiresCoupon   bool       `json:"requires_coupon"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.Type == utils.PromotionPercentage && input.Value > 100 {
+		utils.BadRequestErrorJson(c, "Percentage value can't be greater than 100")
+		return
+	}
+	if input.Type == utils.PromotionBuyXGetY && (input.BuyQuantity == 0 || input.GetQuantity == 0) {
+		utils.BadRequestErrorJson(c, "buy_quantity and get_quantity are required for buy_x_get_y promotions")
+		return
+	}
+	if input.StartsAt != nil && input.EndsAt != nil && input.EndsAt.Before(*input.StartsAt) {
+		utils.BadRequestErrorJson(c, "ends_at must be after starts_at")
+		return
+	}
+
+	sellerID := input.SellerID
+	if c.GetString("user_type") == "seller" {
+		id := c.GetUint("user_id")
+		sellerID = &id
+	}
+
+	if input.ProductID != nil {
+		query := database.GetDB()
+		if sellerID != nil {
+			query = query.Where("seller_id = ?", *sellerID)
+		}
+		if err := query.First(&models.Product{}, *input.ProductID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				utils.NotFoundRequestErrorJson(c, "Product not found")
+				return
+			}
+
+			utils.InternalServerErrorJSON(c, err.Error())
+			return
+		}
+	}
+
+	promotion := models.Promotion{
+		Name:             input.Name,
+		Description:      input.Description,
+		SellerID:         sellerID,
+		Type:             input.Type,
+		Value:            input.Value,
+		ProductID:        input.ProductID,
+		Category:         input.Category,
+		BuyQuantity:      input.BuyQuantity,
+		GetQuantity:      input.GetQuantity,
+		MinSubtotal:      input.MinSubtotal,
+		StartsAt:         input.StartsAt,
+		EndsAt:           input.EndsAt,
+		UsageLimit:       input.UsageLimit,
+		PerCustomerLimit: input.PerCustomerLimit,
+		RequiresCoupon:   input.RequiresCoupon,
+		Active:           true,
+	}
+
+	if err := database.GetDB().Create(&promotion).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetTarget(c, "promotions", strconv.FormatUint(uint64(promotion.ID), 10))
+	audit.SetAfter(c, promotion)
+	utils.JSONResponse(c, http.StatusCreated, promotion)
+}
+
+func UpdatePromotion(c *gin.Context) {
+	promotion, ok := findPromotion(c)
+	if !ok {
+		return
+	}
+
+	audit.SetBefore(c, promotion)
+
+	var input struct {
+		Name             string     `json:"name" binding:"omitempty"`
+		Description      string     `json:"description" binding:"omitempty"`
+		Value            *float64   `json:"value" binding:"omitempty,gte=0"`
+		MinSubtotal      *float64   `json:"min_subtotal" binding:"omitempty,gte=0"`
+		StartsAt         *time.Time `json:"starts_at" binding:"omitempty"`
+		EndsAt           *time.Time `json:"ends_at" binding:"omitempty"`
+		UsageLimit       *int       `json:"usage_limit" binding:"omitempty,gte=0"`
+		PerCustomerLimit *int       `json:"per_customer_limit" binding:"omitempty,gte=0"`
+		Active           *bool      `json:"active" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.Name != "" {
+		promotion.Name = input.Name
+	}
+	if input.Description != "" {
+		promotion.Description = input.Description
+	}
+	if input.Value != nil {
+		if promotion.Type == utils.PromotionPercentage && *input.Value > 100 {
+			utils.BadRequestErrorJson(c, "Percentage value can't be greater than 100")
+			return
+		}
+		promotion.Value = *input.Value
+	}
+	if input.MinSubtotal != nil {
+		promotion.MinSubtotal = *input.MinSubtotal
+	}
+	if input.StartsAt != nil {
+		promotion.StartsAt = input.StartsAt
+	}
+	if input.EndsAt != nil {
+		promotion.EndsAt = input.EndsAt
+	}
+	if input.UsageLimit != nil {
+		promotion.UsageLimit = *input.UsageLimit
+	}
+	if input.PerCustomerLimit != nil {
+		promotion.PerCustomerLimit = *input.PerCustomerLimit
+	}
+	if input.Active != nil {
+		promotion.Active = *input.Active
+	}
+
+	if err := database.GetDB().Omit("Coupons").Save(&promotion).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, promotion)
+	utils.JSONResponse(c, http.StatusOK, promotion)
+}
+
+func DeletePromotion(c *gin.Context) {
+	promotion, ok := findPromotion(c)
+	if !ok {
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&promotion).Update("active", false).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&promotion).Error
+	})
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Promotion deleted successfully"})
+}
+
+func CreateCoupon(c *gin.Context) {
+	promotion, ok := findPromotion(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Code             string `json:"code" binding:"required,alphanum,min=3,max=32"`
+		UsageLimit       int    `json:"usage_limit" binding:"gte=0"`
+		PerCustomerLimit int    `json:"per_customer_limit" binding:"gte=0"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	code := strings.ToUpper(input.Code)
+	err := database.GetDB().Unscoped().Where("code = ?", code).First(&models.Coupon{}).Error
+	if err == nil {
+		utils.ConflictRequestErrorJson(c, "Coupon already exists with the same code")
+		return
+	}
+
+	coupon := models.Coupon{
+		PromotionID:      promotion.ID,
+		Code:             code,
+		UsageLimit:       input.UsageLimit,
+		PerCustomerLimit: input.PerCustomerLimit,
+		Active:           true,
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&coupon).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&promotion).Update("requires_coupon", true).Error
+	})
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetTarget(c, "coupons", strconv.FormatUint(uint64(coupon.ID), 10))
+	audit.SetAfter(c, coupon)
+	utils.JSONResponse(c, http.StatusCreated, coupon)
+}
+
+func DeactivateCoupon(c *gin.Context) {
+	promotion, ok := findPromotion(c)
+	if !ok {
+		return
+	}
+
+	var coupon models.Coupon
+	if err := database.GetDB().Where("promotion_id = ?", promotion.ID).First(&coupon, c.Param("couponId")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Coupon not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetBefore(c, coupon)
+
+	if err := database.GetDB().Model(&coupon).Update("active", false).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, coupon)
+	utils.JSONResponse(c, http.StatusOK, coupon)
+}
+
+func ApplyCartCoupon(c *gin.Context) {
+	customerID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	var cart models.Cart
+	if err := database.GetDB().Where("customer_id = ? AND is_active = ?", customerID, true).First(&cart).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "There is no active cart for this customer.")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	coupon, err := services.FindCoupon(database.GetDB(), input.Code, customerID.(uint), time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrCouponNotFound) {
+			utils.NotFoundRequestErrorJson(c, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrCouponUnavailable) {
+			utils.BadRequestErrorJson(c, err.Error())
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	cart.CouponCode = coupon.Code
+	if err := database.GetDB().Model(&cart).Update("coupon_code", cart.CouponCode).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	respondWithPricedCart(c, &cart, http.StatusOK)
+}
+
+func RemoveCartCoupon(c *gin.Context) {
+	customerID, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var cart models.Cart
+	if err := database.GetDB().Where("customer_id = ? AND is_active = ?", customerID, true).First(&cart).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "There is no active cart for this customer.")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	cart.CouponCode = ""
+	if err := database.GetDB().Model(&cart).Update("coupon_code", cart.CouponCode).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	respondWithPricedCart(c, &cart, http.StatusOK)
+}
+
+func promotionScope(c *gin.Context) *gorm.DB {
+	query := database.GetDB()
+	if c.GetString("user_type") == "seller" {
+		query = query.Where("seller_id = ?", c.GetUint("user_id"))
+	}
+
+	return query
+}
+
+func findPromotion(c *gin.Context) (models.Promotion, bool) {
+	var promotion models.Promotion
+	if err := promotionScope(c).Preload("Coupons").First(&promotion, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Promotion not found")
+			return promotion, false
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return promotion, false
+	}
+
+	return promotion, true
+}
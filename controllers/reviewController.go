@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"api/audit"
+	"api/database"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+func GetProductReviews(c *gin.Context) {
+	var reviews []models.Review
+	if err := database.GetDB().Where("product_id = ? AND is_hidden = ?", c.Param("id"), false).Order("created_at DESC").Find(&reviews).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(reviews) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No reviews found for this product")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, reviews)
+}
+
+func CreateReview(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var input struct {
+		Rating int    `json:"rating" binding:"required,min=1,max=5"`
+		Title  string `json:"title" binding:"omitempty,max=200"`
+		Body   string `json:"body" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	var product models.Product
+	if err := database.GetDB().First(&product, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Product not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	review := models.Review{
+		ProductID:  product.ID,
+		CustomerID: customerId.(uint),
+		Rating:     input.Rating,
+		Title:      input.Title,
+		Body:       input.Body,
+	}
+
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		return services.CreateReview(tx, &review)
+	}); err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotVerifiedPurchase):
+			utils.ForbiddenRequestErrorJson(c, "Only customers who received this product can review it")
+		case errors.Is(err, services.ErrReviewExists):
+			utils.ConflictRequestErrorJson(c, "You have already reviewed this product")
+		default:
+			utils.InternalServerErrorJSON(c, err.Error())
+		}
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, review)
+}
+
+func UpdateReview(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var input struct {
+		Rating int    `json:"rating" binding:"omitempty,min=1,max=5"`
+		Title  string `json:"title" binding:"omitempty,max=200"`
+		Body   string `json:"body" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	var review models.Review
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("customer_id = ?", customerId).First(&review, c.Param("id")).Error; err != nil {
+			return err
+		}
+
+		if input.Rating != 0 {
+			review.Rating = input.Rating
+		}
+		if input.Title != "" {
+			review.Title = input.Title
+		}
+		if input.Body != "" {
+			review.Body = input.Body
+		}
+
+		if err := tx.Save(&review).Error; err != nil {
+			return err
+		}
+
+		return services.RefreshProductRating(tx, review.ProductID)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Review not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, review)
+}
+
+func DeleteReview(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var review models.Review
+		if err := tx.Where("customer_id = ?", customerId).First(&review, c.Param("id")).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&review).Error; err != nil {
+			return err
+		}
+
+		return services.RefreshProductRating(tx, review.ProductID)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Review not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Review deleted successfully"})
+}
+
+func GetSellerReviews(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var reviews []models.Review
+	if err := database.GetDB().
+		Where("product_id IN (SELECT id FROM products WHERE seller_id = ?)", sellerId).
+		Order("created_at DESC").
+		Find(&reviews).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(reviews) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No reviews found for this seller")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, reviews)
+}
+
+func ReplyToReview(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var input struct {
+		Reply string `json:"reply" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	var review models.Review
+	if err := database.GetDB().
+		Where("product_id IN (SELECT id FROM products WHERE seller_id = ?)", sellerId).
+		First(&review, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Review not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	now := time.Now()
+	review.SellerReply = input.Reply
+	review.SellerRepliedAt = &now
+	if err := database.GetDB().Save(&review).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, review)
+}
+
+func GetReviews(c *gin.Context) {
+	query := database.GetDB().Order("created_at DESC")
+	if productID := c.Query("product_id"); productID != "" {
+		query = query.Where("product_id = ?", productID)
+	}
+	if hidden := c.Query("hidden"); hidden != "" {
+		query = query.Where("is_hidden = ?", hidden == "true")
+	}
+
+	var reviews []models.Review
+	if err := query.Find(&reviews).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(reviews) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No reviews found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, reviews)
+}
+
+func ModerateReview(c *gin.Context) {
+	var input struct {
+		Hidden *bool  `json:"hidden" binding:"required"`
+		Reason string `json:"reason" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	var review models.Review
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&review, c.Param("id")).Error; err != nil {
+			return err
+		}
+
+		audit.SetBefore(c, review)
+
+		review.IsHidden = *input.Hidden
+		review.HiddenReason = ""
+		if review.IsHidden {
+			review.HiddenReason = input.Reason
+		}
+
+		if err := tx.Save(&review).Error; err != nil {
+			return err
+		}
+
+		return services.RefreshProductRating(tx, review.ProductID)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Review not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, review)
+	utils.JSONResponse(c, http.StatusOK, review)
+}
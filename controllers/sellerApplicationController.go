@@ -0,0 +1,337 @@
+package controllers
+
+import (
+	"api/audit"
+	"api/database"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+const maxSellerDocumentSize = 10 << 20
+
+var allowedSellerDocumentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+func GetSellerApplication(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var application models.SellerApplication
+	if err := database.GetDB().Preload("Documents").Where("seller_id = ?", sellerId).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "No application found for this seller")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, application)
+}
+
+func SubmitSellerApplication(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var input struct {
+		BusinessName       string               `json:"business_name" binding:"required"`
+		BusinessType       string               `json:"business_type" binding:"required,oneof=individual company partnership"`
+		RegistrationNumber string               `json:"registration_number" binding:"omitempty"`
+		TaxID              string               `json:"tax_id" binding:"required"`
+		BusinessAddress    models.AddressFields `json:"business_address" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	address := utils.NormalizeAddress(input.BusinessAddress)
+	if errs := utils.ValidateAddress(address); len(errs) > 0 {
+		utils.AddressValidationErrorJson(c, errs)
+		return
+	}
+
+	var application models.SellerApplication
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		application, err = services.SubmitSellerApplication(tx, sellerId.(uint), models.SellerApplication{
+			BusinessName:       input.BusinessName,
+			BusinessType:       input.BusinessType,
+			RegistrationNumber: input.RegistrationNumber,
+			TaxID:              input.TaxID,
+			BusinessAddress:    address,
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrSellerAlreadyApproved) {
+			utils.ConflictRequestErrorJson(c, "Seller has already been approved")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, application)
+}
+
+func UploadSellerDocument(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var application models.SellerApplication
+	if err := database.GetDB().Where("seller_id = ?", sellerId).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Submit the application before uploading documents")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if application.Status != utils.SellerStatusPending {
+		utils.ConflictRequestErrorJson(c, "Documents can only be added while the application is pending review")
+		return
+	}
+
+	documentType := c.PostForm("document_type")
+	if documentType == "" {
+		utils.BadRequestErrorJson(c, "document_type is required")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequestErrorJson(c, "file is required")
+		return
+	}
+
+	if fileHeader.Size > maxSellerDocumentSize {
+		utils.BadRequestErrorJson(c, "Documents must be 10MB or smaller")
+		return
+	}
+
+	contentType := fileHeader.Header.Get("Content-Type")
+	if !allowedSellerDocumentTypes[contentType] {
+		utils.BadRequestErrorJson(c, "Documents must be PDF, JPEG or PNG files")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+	defer file.Close()
+
+	name := fmt.Sprintf("sellers/%d/%d-%s", application.SellerID, time.Now().UnixNano(), filepath.Base(fileHeader.Filename))
+	path, err := services.GetDocumentStorage().Save(name, file)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	document := models.SellerDocument{
+		SellerApplicationID: application.ID,
+		DocumentType:        documentType,
+		FileName:            filepath.Base(fileHeader.Filename),
+		ContentType:         contentType,
+		Size:                fileHeader.Size,
+		StoragePath:         path,
+	}
+	if err := database.GetDB().Create(&document).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, document)
+}
+
+func GetSellerApplications(c *gin.Context) {
+	query := database.GetDB().Preload("Seller").Order("submitted_at")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var applications []models.SellerApplication
+	if err := query.Find(&applications).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(applications) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No seller applications found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, applications)
+}
+
+func GetSellerApplicationForReview(c *gin.Context) {
+	var application models.SellerApplication
+	if err := database.GetDB().Preload("Seller").Preload("Documents").First(&application, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Seller application not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, application)
+}
+
+func DownloadSellerDocument(c *gin.Context) {
+	var document models.SellerDocument
+	if err := database.GetDB().Where("seller_application_id = ?", c.Param("id")).First(&document, c.Param("documentId")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Document not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	content, err := services.GetDocumentStorage().Open(document.StoragePath)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, document.Size, document.ContentType, content, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", document.FileName),
+	})
+}
+
+func ApproveSellerApplication(c *gin.Context) {
+	reviewSellerApplication(c, true)
+}
+
+func RejectSellerApplication(c *gin.Context) {
+	reviewSellerApplication(c, false)
+}
+
+func reviewSellerApplication(c *gin.Context, approve bool) {
+	adminId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Admin is not authenticated")
+		return
+	}
+
+	var input struct {
+		Notes string `json:"notes" binding:"omitempty"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			var verr validator.ValidationErrors
+			if errors.As(err, &verr) {
+				utils.ValidationErrorJson(c, verr)
+				return
+			}
+
+			utils.BadRequestErrorJson(c, err.Error())
+			return
+		}
+	}
+
+	if !approve && input.Notes == "" {
+		utils.BadRequestErrorJson(c, "A rejection reason is required in notes")
+		return
+	}
+
+	var application models.SellerApplication
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		application, err = services.ReviewSellerApplication(tx, c.Param("id"), adminId.(uint), approve, input.Notes)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundRequestErrorJson(c, "Seller application not found")
+		case errors.Is(err, services.ErrApplicationNotPending):
+			utils.ConflictRequestErrorJson(c, "Seller application has already been reviewed")
+		case errors.Is(err, services.ErrApplicationIncomplete):
+			utils.ConflictRequestErrorJson(c, "Seller application has no documents")
+		default:
+			utils.InternalServerErrorJSON(c, err.Error())
+		}
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, application)
+}
+
+func UpdateSellerStatus(c *gin.Context) {
+	var input struct {
+		Status string `json:"status" binding:"required,oneof=approved suspended"`
+		Reason string `json:"reason" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	var seller models.Seller
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		seller, err = services.SetSellerStatus(tx, c.Param("id"), input.Status, input.Reason)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundRequestErrorJson(c, "seller not found")
+		case errors.Is(err, services.ErrInvalidSellerStatus):
+			utils.ConflictRequestErrorJson(c, "Only approved sellers can be suspended and only suspended sellers reinstated")
+		default:
+			utils.InternalServerErrorJSON(c, err.Error())
+		}
+		return
+	}
+
+	audit.SetAfter(c, seller)
+	utils.JSONResponse(c, http.StatusOK, seller)
+}
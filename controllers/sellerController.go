@@ -1,14 +1,18 @@
 package controllers
 
 import (
+	"api/audit"
 	"api/database"
 	"api/models"
+	"api/services"
 	"api/utils"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func GetSellers(c *gin.Context) {
@@ -30,6 +34,7 @@ func GetSeller(c *gin.Context) {
 	if err := database.GetDB().First(&seller, c.Param("id")).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.NotFoundRequestErrorJson(c, "seller not found")
+			return
 		}
 
 		utils.InternalServerErrorJSON(c, err.Error())
@@ -45,8 +50,8 @@ func GetSellerProfile(c *gin.Context) {
 		return
 	}
 
-	c.AddParam("id", sellerId.(string))
-	GetCustomer(c)
+	c.AddParam("id", strconv.FormatUint(uint64(sellerId.(uint)), 10))
+	GetSeller(c)
 }
 
 func CreateSeller(c *gin.Context) {
@@ -88,6 +93,12 @@ func CreateSeller(c *gin.Context) {
 		return
 	}
 
+	storeSlug, err := services.UniqueStoreSlug(database.GetDB(), sellerInput.StoreName, 0)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
 	newSeller := models.Seller{
 		User: models.User{
 			Email:    sellerInput.Email,
@@ -95,7 +106,10 @@ func CreateSeller(c *gin.Context) {
 			Password: hashedPassword,
 			Name:     sellerInput.Name,
 		},
-		StoreName: sellerInput.StoreName,
+		StoreName:   sellerInput.StoreName,
+		StoreSlug:   storeSlug,
+		StoreStatus: utils.StoreStatusOpen,
+		Status:      utils.SellerStatusPending,
 	}
 
 	if err := database.GetDB().Create(&newSeller).Error; err != nil {
@@ -120,12 +134,23 @@ func UpdateSeller(c *gin.Context) {
 		return
 	}
 
+	audit.SetBefore(c, existingSeller)
+
 	var sellerInput struct {
-		Email     string `json:"email" binding:"omitempty,email"`
-		Name      string `json:"name" binding:"omitempty"`
-		Phone     string `json:"phone" binding:"omitempty"`
-		Password  string `json:"password" binding:"omitempty,min=6"`
-		StoreName string `json:"store_name" binding:"omitempty"`
+		Email            string  `json:"email" binding:"omitempty,email"`
+		Name             string  `json:"name" binding:"omitempty"`
+		Phone            string  `json:"phone" binding:"omitempty"`
+		Password         string  `json:"password" binding:"omitempty,min=6"`
+		StoreName        string  `json:"store_name" binding:"omitempty"`
+		StoreSlug        string  `json:"store_slug" binding:"omitempty"`
+		StoreDescription *string `json:"store_description" binding:"omitempty"`
+		LogoURL          *string `json:"logo_url" binding:"omitempty,url"`
+		ReturnPolicy     *string `json:"return_policy" binding:"omitempty"`
+		ShippingPolicy   *string `json:"shipping_policy" binding:"omitempty"`
+		ContactEmail     *string `json:"contact_email" binding:"omitempty,email"`
+		ContactPhone     *string `json:"contact_phone" binding:"omitempty"`
+		StoreStatus      string  `json:"store_status" binding:"omitempty,oneof=open vacation"`
+		VacationMessage  *string `json:"vacation_message" binding:"omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&sellerInput); err != nil {
@@ -171,12 +196,47 @@ func UpdateSeller(c *gin.Context) {
 	if sellerInput.StoreName != "" {
 		existingSeller.StoreName = sellerInput.StoreName
 	}
+	if sellerInput.StoreSlug != "" {
+		slug := utils.Slugify(sellerInput.StoreSlug)
+		var seller models.Seller
+		err := database.GetDB().Where("store_slug = ? AND id <> ?", slug, existingSeller.ID).First(&seller).Error
+		if slug == "" || err == nil {
+			utils.ConflictRequestErrorJson(c, "Store slug is not available")
+			return
+		}
+		existingSeller.StoreSlug = slug
+	}
+	if sellerInput.StoreDescription != nil {
+		existingSeller.StoreDescription = *sellerInput.StoreDescription
+	}
+	if sellerInput.LogoURL != nil {
+		existingSeller.LogoURL = *sellerInput.LogoURL
+	}
+	if sellerInput.ReturnPolicy != nil {
+		existingSeller.ReturnPolicy = *sellerInput.ReturnPolicy
+	}
+	if sellerInput.ShippingPolicy != nil {
+		existingSeller.ShippingPolicy = *sellerInput.ShippingPolicy
+	}
+	if sellerInput.ContactEmail != nil {
+		existingSeller.ContactEmail = strings.TrimSpace(*sellerInput.ContactEmail)
+	}
+	if sellerInput.ContactPhone != nil {
+		existingSeller.ContactPhone = *sellerInput.ContactPhone
+	}
+	if sellerInput.StoreStatus != "" {
+		existingSeller.StoreStatus = sellerInput.StoreStatus
+	}
+	if sellerInput.VacationMessage != nil {
+		existingSeller.VacationMessage = *sellerInput.VacationMessage
+	}
 
-	if err := database.GetDB().Save(&existingSeller).Error; err != nil {
+	if err := database.GetDB().Omit("average_rating", "rating_count").Save(&existingSeller).Error; err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
+	audit.SetAfter(c, existingSeller)
 	utils.JSONResponse(c, http.StatusOK, existingSeller)
 }
 
@@ -187,23 +247,69 @@ func UpdateSellerProfile(c *gin.Context) {
 		return
 	}
 
-	c.AddParam("id", sellerId.(string))
+	c.AddParam("id", strconv.FormatUint(uint64(sellerId.(uint)), 10))
 	UpdateSeller(c)
 }
 
 func DeleteSeller(c *gin.Context) {
-	customerID := c.Param("id")
+	sellerID := c.Param("id")
 
 	var existingSeller models.Seller
-	if err := database.GetDB().First(&existingSeller, customerID).Error; err != nil {
+	if err := database.GetDB().First(&existingSeller, sellerID).Error; err != nil {
 		utils.NotFoundRequestErrorJson(c, "seller not found")
 		return
 	}
 
-	if err := database.GetDB().Unscoped().Delete(&existingSeller).Error; err != nil {
+	audit.SetBefore(c, existingSeller)
+
+	if err := database.GetDB().Delete(&existingSeller).Error; err != nil {
 		utils.InternalServerErrorJSON(c, err.Error())
 		return
 	}
 
 	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Seller deleted successfully"})
 }
+
+func GetDeletedSellers(c *gin.Context) {
+	var sellers []models.Seller
+	if err := services.OnlyDeleted(database.GetDB()).Order("deleted_at DESC").Find(&sellers).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(sellers) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No deleted sellers found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, sellers)
+}
+
+func RestoreSeller(c *gin.Context) {
+	var seller models.Seller
+	if err := services.OnlyDeleted(database.GetDB()).First(&seller, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Deleted seller not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetBefore(c, seller)
+
+	if err := services.RestoreSeller(database.GetDB(), &seller); err != nil {
+		if errors.Is(err, services.ErrRestoreConflict) {
+			utils.ConflictRequestErrorJson(c, "Another seller already uses this email or phone")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, seller)
+
+	utils.JSONResponse(c, http.StatusOK, seller)
+}
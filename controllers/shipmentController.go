@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"api/database"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+func CreateShipment(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var subOrder models.SubOrder
+	if err := database.GetDB().Where("seller_id = ?", sellerId).First(&subOrder, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Sub-order not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if subOrder.Status == utils.StatusCancelled {
+		utils.BadRequestErrorJson(c, "Cannot ship a cancelled sub-order")
+		return
+	}
+
+	var input struct {
+		Carrier        string `json:"carrier" binding:"required"`
+		TrackingNumber string `json:"tracking_number" binding:"required"`
+		Items          []struct {
+			CartItemID uint `json:"cart_item_id" binding:"required"`
+			Quantity   int  `json:"quantity" binding:"required,gt=0"`
+		} `json:"items" binding:"required,min=1,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	var lines []services.ShipmentLine
+	for _, item := range input.Items {
+		lines = append(lines, services.ShipmentLine{CartItemID: item.CartItemID, Quantity: item.Quantity})
+	}
+
+	shipment, err := services.CreateShipment(database.GetDB(), subOrder, input.Carrier, input.TrackingNumber, lines)
+	if err != nil {
+		if errors.Is(err, services.ErrShipmentItemNotInSubOrder) || errors.Is(err, services.ErrShipmentQuantityExceeded) || errors.Is(err, services.ErrEmptyShipment) ||
+			errors.Is(err, services.ErrShipmentItemNotPending) || errors.Is(err, services.ErrUnknownCarrier) {
+			utils.BadRequestErrorJson(c, err.Error())
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, shipment)
+}
+
+func GetSubOrderShipments(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var shipments []models.Shipment
+	if err := database.GetDB().Preload("Items").Preload("Events").Where("seller_id = ? AND sub_order_id = ?", sellerId, c.Param("id")).Find(&shipments).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(shipments) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No shipments found for this sub-order")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, shipments)
+}
+
+func MarkShipmentDelivered(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var shipment models.Shipment
+	if err := database.GetDB().Where("seller_id = ?", sellerId).First(&shipment, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Shipment not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if shipment.DeliveredAt != nil {
+		utils.ConflictRequestErrorJson(c, "Shipment is already delivered")
+		return
+	}
+
+	if err := services.MarkShipmentDelivered(database.GetDB(), &shipment, time.Now()); err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, shipment)
+}
+
+func GetCustomerOrderTracking(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var order models.Order
+	if err := database.GetDB().Where("cart_id IN (SELECT id FROM carts WHERE customer_id = ?)", customerId).First(&order, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Order not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	var shipments []models.Shipment
+	if err := database.GetDB().Preload("Items.CartItem.Product", services.IncludeDeleted).Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("occurred_at")
+	}).Where("order_id = ?", order.ID).Find(&shipments).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(shipments) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No shipments found for this order")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, shipments)
+}
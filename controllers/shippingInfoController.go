@@ -12,7 +12,7 @@ import (
 
 func GetOrderShippingInfo(c *gin.Context) {
 	var shippingInfo models.ShippingInfo
-	if err := database.GetDB().Where("order_id = ?", c.Param("id")).First(&shippingInfo).Error; err != nil {
+	if err := database.GetDB().Where("order_id = ? AND sub_order_id IS NULL", c.Param("id")).First(&shippingInfo).Error; err != nil {
 		utils.ErrorJSON(c, http.StatusNotFound, gin.H{
 			"error":   err.Error(),
 			"message": "Order shipping info not found",
@@ -31,7 +31,7 @@ func GetSellerOrderShippingInfo(c *gin.Context) {
 	}
 
 	var order models.Order
-	if err := database.GetDB().Preload("ShippingInfo").Preload("Cart.CartItems", "product_id IN (?)", database.GetDB().Model(models.Product{})).Where("seller_id = ?", sellerId).First(&order, c.Param("id")).Error; err != nil {
+	if err := database.GetDB().Preload("ShippingInfo", "sub_order_id IS NULL").Preload("Cart.CartItems", "product_id IN (?)", database.GetDB().Model(models.Product{})).Where("seller_id = ?", sellerId).First(&order, c.Param("id")).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.NotFoundRequestErrorJson(c, "Order not found")
 			return
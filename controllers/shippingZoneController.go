@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"api/database"
+	"api/models"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+func GetShippingZones(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var zones []models.ShippingZone
+	if err := database.GetDB().Preload("Rates").Where("seller_id = ?", sellerId).Find(&zones).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(zones) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No shipping zones found for this seller")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, zones)
+}
+
+func CreateShippingZone(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var input struct {
+		Name      string   `json:"name" binding:"required"`
+		Countries []string `json:"countries" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	zone := models.ShippingZone{
+		SellerID:  sellerId.(uint),
+		Name:      input.Name,
+		Countries: joinCountryCodes(input.Countries),
+	}
+
+	if err := database.GetDB().Create(&zone).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, zone)
+}
+
+func UpdateShippingZone(c *gin.Context) {
+	zone, ok := findSellerShippingZone(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name      string   `json:"name" binding:"omitempty"`
+		Countries []string `json:"countries" binding:"omitempty,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.Name != "" {
+		zone.Name = input.Name
+	}
+	if len(input.Countries) > 0 {
+		zone.Countries = joinCountryCodes(input.Countries)
+	}
+
+	if err := database.GetDB().Save(&zone).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, zone)
+}
+
+func DeleteShippingZone(c *gin.Context) {
+	zone, ok := findSellerShippingZone(c)
+	if !ok {
+		return
+	}
+
+	if err := database.GetDB().Unscoped().Delete(&zone).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Shipping zone deleted successfully"})
+}
+
+func CreateShippingRate(c *gin.Context) {
+	zone, ok := findSellerShippingZone(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name          string  `json:"name" binding:"required"`
+		Type          string  `json:"type" binding:"required,oneof=flat weight free_over"`
+		Amount        float64 `json:"amount" binding:"gte=0"`
+		PerKg         float64 `json:"per_kg" binding:"gte=0"`
+		FreeThreshold float64 `json:"free_threshold" binding:"gte=0"`
+		MinWeight     float64 `json:"min_weight" binding:"gte=0"`
+		MaxWeight     float64 `json:"max_weight" binding:"gte=0"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.MaxWeight > 0 && input.MaxWeight < input.MinWeight {
+		utils.BadRequestErrorJson(c, "max_weight must be greater than min_weight")
+		return
+	}
+
+	rate := models.ShippingRate{
+		ShippingZoneID: zone.ID,
+		Name:           input.Name,
+		Type:           input.Type,
+		Amount:         input.Amount,
+		PerKg:          input.PerKg,
+		FreeThreshold:  input.FreeThreshold,
+		MinWeight:      input.MinWeight,
+		MaxWeight:      input.MaxWeight,
+	}
+
+	if err := database.GetDB().Create(&rate).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, rate)
+}
+
+func DeleteShippingRate(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var rate models.ShippingRate
+	if err := database.GetDB().
+		Where("shipping_zone_id IN (?)", database.GetDB().Model(&models.ShippingZone{}).Select("id").Where("seller_id = ?", sellerId)).
+		First(&rate, c.Param("rateId")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Shipping rate not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if err := database.GetDB().Unscoped().Delete(&rate).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Shipping rate deleted successfully"})
+}
+
+func findSellerShippingZone(c *gin.Context) (models.ShippingZone, bool) {
+	var zone models.ShippingZone
+
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return zone, false
+	}
+
+	if err := database.GetDB().Where("seller_id = ?", sellerId).First(&zone, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Shipping zone not found")
+			return zone, false
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return zone, false
+	}
+
+	return zone, true
+}
+
+func joinCountryCodes(countries []string) string {
+	var codes []string
+	for _, country := range countries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if country != "" {
+			codes = append(codes, country)
+		}
+	}
+
+	return strings.Join(codes, ",")
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"api/database"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+func GetStore(c *gin.Context) {
+	var seller models.Seller
+	if err := database.GetDB().Where("store_slug = ? AND status = ?", c.Param("slug"), utils.SellerStatusApproved).First(&seller).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Store not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	products := []models.Product{}
+	if seller.StoreStatus == utils.StoreStatusOpen {
+		if err := services.VisibleProducts(database.GetDB()).Where("seller_id = ?", seller.ID).Find(&products).Error; err != nil {
+			utils.InternalServerErrorJSON(c, err.Error())
+			return
+		}
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{
+		"store_name":        seller.StoreName,
+		"store_slug":        seller.StoreSlug,
+		"store_description": seller.StoreDescription,
+		"logo_url":          seller.LogoURL,
+		"return_policy":     seller.ReturnPolicy,
+		"shipping_policy":   seller.ShippingPolicy,
+		"contact_email":     seller.ContactEmail,
+		"contact_phone":     seller.ContactPhone,
+		"store_status":      seller.StoreStatus,
+		"vacation_message":  seller.VacationMessage,
+		"average_rating":    seller.AverageRating,
+		"rating_count":      seller.RatingCount,
+		"products":          products,
+	})
+}
+
+func RateSubOrderSeller(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var input struct {
+		Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+		Comment string `json:"comment" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	subOrderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequestErrorJson(c, "Invalid sub-order id")
+		return
+	}
+
+	rating := models.SellerRating{
+		SubOrderID: uint(subOrderID),
+		CustomerID: customerId.(uint),
+		Rating:     input.Rating,
+		Comment:    input.Comment,
+	}
+
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		return services.RateSeller(tx, &rating)
+	}); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundRequestErrorJson(c, "Sub-order not found")
+		case errors.Is(err, services.ErrSubOrderNotDelivered):
+			utils.ForbiddenRequestErrorJson(c, "Only delivered orders can be rated")
+		case errors.Is(err, services.ErrSellerAlreadyRated):
+			utils.ConflictRequestErrorJson(c, "This order has already been rated")
+		default:
+			utils.InternalServerErrorJSON(c, err.Error())
+		}
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, rating)
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"api/audit"
+	"api/database"
+	"api/events"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func GetSellerSubOrders(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	query := database.GetDB().Preload("ShippingInfo").Preload("CartItems.Product", services.IncludeDeleted).Where("seller_id = ?", sellerId)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var subOrders []models.SubOrder
+	if err := query.Order("created_at DESC").Find(&subOrders).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(subOrders) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No sub-orders found for this seller")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, subOrders)
+}
+
+func GetSellerSubOrder(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var subOrder models.SubOrder
+	if err := database.GetDB().Preload("ShippingInfo").Preload("CartItems.Product", services.IncludeDeleted).Where("seller_id = ?", sellerId).First(&subOrder, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Sub-order not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, subOrder)
+}
+
+func UpdateSubOrderStatus(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var subOrder models.SubOrder
+	if err := database.GetDB().Where("seller_id = ?", sellerId).First(&subOrder, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Sub-order not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	var input struct {
+		Status         string `json:"status" binding:"required"`
+		Carrier        string `json:"carrier" binding:"omitempty"`
+		TrackingNumber string `json:"tracking_number" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	audit.SetBefore(c, subOrder)
+
+	previousStatus := subOrder.Status
+	switch input.Status {
+	case utils.StatusPending, utils.StatusShipped, utils.StatusDelivered, utils.StatusCancelled:
+		subOrder.Status = input.Status
+	default:
+		utils.BadRequestErrorJson(c, "Invalid status, it should be either "+utils.StatusPending+" or "+utils.StatusShipped+" or "+utils.StatusCancelled+" or "+utils.StatusDelivered)
+		return
+	}
+
+	if input.Carrier != "" {
+		subOrder.Carrier = input.Carrier
+	}
+	if input.TrackingNumber != "" {
+		subOrder.TrackingNumber = input.TrackingNumber
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&subOrder).Error; err != nil {
+			return err
+		}
+
+		if err := events.RecordSubOrderStatusChange(tx, subOrder, previousStatus); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.CartItem{}).Where("sub_order_id = ?", subOrder.ID).Update("status", subOrder.Status).Error; err != nil {
+			return err
+		}
+
+		return services.RefreshOrderStatus(tx, subOrder.OrderID)
+	})
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, subOrder)
+	utils.JSONResponse(c, http.StatusOK, subOrder)
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"api/audit"
+	"api/database"
+	"api/models"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+func GetTaxRates(c *gin.Context) {
+	query := database.GetDB()
+	if country := c.Query("country"); country != "" {
+		query = query.Where("country = ?", strings.ToUpper(country))
+	}
+
+	var rates []models.TaxRate
+	if err := query.Order("country, region, tax_class").Find(&rates).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(rates) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No tax rates found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, rates)
+}
+
+func CreateTaxRate(c *gin.Context) {
+	var input struct {
+		Name      string  `json:"name" binding:"required"`
+		Country   string  `json:"country" binding:"required,len=2"`
+		Region    string  `json:"region" binding:"omitempty"`
+		TaxClass  string  `json:"tax_class" binding:"omitempty"`
+		Rate      float64 `json:"rate" binding:"gte=0,lte=1"`
+		Inclusive bool    `json:"inclusive"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.TaxClass == "" {
+		input.TaxClass = utils.DefaultTaxClass
+	}
+
+	rate := models.TaxRate{
+		Name:      input.Name,
+		Country:   strings.ToUpper(input.Country),
+		Region:    strings.TrimSpace(input.Region),
+		TaxClass:  input.TaxClass,
+		Rate:      input.Rate,
+		Inclusive: input.Inclusive,
+	}
+
+	err := database.GetDB().Where("country = ? AND region = ? AND tax_class = ?", rate.Country, rate.Region, rate.TaxClass).First(&models.TaxRate{}).Error
+	if err == nil {
+		utils.ConflictRequestErrorJson(c, "Tax rate already exists for this country, region and tax class")
+		return
+	}
+
+	if err := database.GetDB().Create(&rate).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, rate)
+}
+
+func UpdateTaxRate(c *gin.Context) {
+	var rate models.TaxRate
+	if err := database.GetDB().First(&rate, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Tax rate not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetBefore(c, rate)
+
+	var input struct {
+		Name      string   `json:"name" binding:"omitempty"`
+		Rate      *float64 `json:"rate" binding:"omitempty,gte=0,lte=1"`
+		Inclusive *bool    `json:"inclusive" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.Name != "" {
+		rate.Name = input.Name
+	}
+	if input.Rate != nil {
+		rate.Rate = *input.Rate
+	}
+	if input.Inclusive != nil {
+		rate.Inclusive = *input.Inclusive
+	}
+
+	if err := database.GetDB().Save(&rate).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	audit.SetAfter(c, rate)
+	utils.JSONResponse(c, http.StatusOK, rate)
+}
+
+func DeleteTaxRate(c *gin.Context) {
+	var rate models.TaxRate
+	if err := database.GetDB().First(&rate, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Tax rate not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if err := database.GetDB().Unscoped().Delete(&rate).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Tax rate deleted successfully"})
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"api/database"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+func GetWebhookEndpoints(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var endpoints []models.WebhookEndpoint
+	if err := database.GetDB().Where("seller_id = ?", sellerId).Order("id").Find(&endpoints).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(endpoints) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No webhook endpoints found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, endpoints)
+}
+
+func GetWebhookEndpoint(c *gin.Context) {
+	endpoint, ok := findSellerWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, endpoint)
+}
+
+func CreateWebhookEndpoint(c *gin.Context) {
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return
+	}
+
+	var input struct {
+		URL         string   `json:"url" binding:"required,url"`
+		Description string   `json:"description" binding:"omitempty,max=200"`
+		EventTypes  []string `json:"event_types" binding:"required,min=1,dive,oneof=order.placed order_item.status_changed order_item.cancelled sub_order.status_changed product.created product.updated product.deleted"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if err := services.ValidateWebhookURL(c.Request.Context(), input.URL); err != nil {
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	secret, err := services.NewWebhookSecret()
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	endpoint := models.WebhookEndpoint{
+		SellerID:    sellerId.(uint),
+		URL:         input.URL,
+		Description: input.Description,
+		Secret:      secret,
+		EventTypes:  strings.Join(input.EventTypes, ","),
+		IsActive:    true,
+	}
+	if err := database.GetDB().Create(&endpoint).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	// The secret is only ever shown here; sellers need it to verify the
+	// X-Webhook-Signature header.
+	utils.JSONResponse(c, http.StatusCreated, gin.H{"endpoint": endpoint, "secret": secret})
+}
+
+func UpdateWebhookEndpoint(c *gin.Context) {
+	endpoint, ok := findSellerWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		URL         string   `json:"url" binding:"omitempty,url"`
+		Description *string  `json:"description" binding:"omitempty,max=200"`
+		EventTypes  []string `json:"event_types" binding:"omitempty,min=1,dive,oneof=order.placed order_item.status_changed order_item.cancelled sub_order.status_changed product.created product.updated product.deleted"`
+		IsActive    *bool    `json:"is_active" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.URL != "" {
+		if err := services.ValidateWebhookURL(c.Request.Context(), input.URL); err != nil {
+			utils.BadRequestErrorJson(c, err.Error())
+			return
+		}
+		endpoint.URL = input.URL
+	}
+	if input.Description != nil {
+		endpoint.Description = *input.Description
+	}
+	if len(input.EventTypes) > 0 {
+		endpoint.EventTypes = strings.Join(input.EventTypes, ",")
+	}
+	if input.IsActive != nil {
+		endpoint.IsActive = *input.IsActive
+		if endpoint.IsActive {
+			endpoint.ConsecutiveFailures = 0
+			endpoint.DisabledAt = nil
+			endpoint.DisabledReason = ""
+		}
+	}
+
+	if err := database.GetDB().Save(&endpoint).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, endpoint)
+}
+
+func DeleteWebhookEndpoint(c *gin.Context) {
+	endpoint, ok := findSellerWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	if err := database.GetDB().Unscoped().Delete(&endpoint).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Webhook endpoint deleted successfully"})
+}
+
+func GetWebhookDeliveries(c *gin.Context) {
+	endpoint, ok := findSellerWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	query := database.GetDB().Where("webhook_endpoint_id = ?", endpoint.ID).Order("created_at DESC").Limit(100)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if eventType := c.Query("event_type"); eventType != "" {
+		query = query.Where("event_type = ?", eventType)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(deliveries) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No webhook deliveries found")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, deliveries)
+}
+
+func SendTestWebhook(c *gin.Context) {
+	endpoint, ok := findSellerWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := services.SendTestWebhook(c.Request.Context(), database.GetDB(), endpoint)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, delivery)
+}
+
+func findSellerWebhookEndpoint(c *gin.Context) (models.WebhookEndpoint, bool) {
+	var endpoint models.WebhookEndpoint
+
+	sellerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Seller is not authenticated")
+		return endpoint, false
+	}
+
+	if err := database.GetDB().Where("seller_id = ?", sellerId).First(&endpoint, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Webhook endpoint not found")
+			return endpoint, false
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return endpoint, false
+	}
+
+	return endpoint, true
+}
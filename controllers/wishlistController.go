@@ -0,0 +1,345 @@
+package controllers
+
+import (
+	"api/database"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func GetWishlists(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var wishlists []models.Wishlist
+	if err := database.GetDB().Preload("Items.Product").Where("customer_id = ?", customerId).Order("created_at").Find(&wishlists).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	if len(wishlists) == 0 {
+		utils.NotFoundRequestErrorJson(c, "No wishlists found for this customer")
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, wishlists)
+}
+
+func GetWishlist(c *gin.Context) {
+	wishlist, ok := findCustomerWishlist(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, wishlist)
+}
+
+func CreateWishlist(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var input struct {
+		Name     string `json:"name" binding:"required"`
+		IsPublic bool   `json:"is_public"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	wishlist, err := services.CreateWishlist(database.GetDB(), customerId.(uint), input.Name, input.IsPublic, false)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, wishlist)
+}
+
+func UpdateWishlist(c *gin.Context) {
+	wishlist, ok := findCustomerWishlist(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name     string `json:"name" binding:"omitempty"`
+		IsPublic *bool  `json:"is_public" binding:"omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.Name != "" {
+		wishlist.Name = input.Name
+	}
+	if input.IsPublic != nil {
+		wishlist.IsPublic = *input.IsPublic
+	}
+
+	if err := database.GetDB().Omit("Items").Save(&wishlist).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, wishlist)
+}
+
+func DeleteWishlist(c *gin.Context) {
+	wishlist, ok := findCustomerWishlist(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("wishlist_id = ?", wishlist.ID).Delete(&models.WishlistItem{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&wishlist).Error
+	})
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Wishlist deleted successfully"})
+}
+
+func AddWishlistItem(c *gin.Context) {
+	wishlist, ok := findCustomerWishlist(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var input struct {
+		ProductID uint `json:"product_id" binding:"required"`
+		Quantity  int  `json:"quantity" binding:"omitempty,gt=0"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var verr validator.ValidationErrors
+		if errors.As(err, &verr) {
+			utils.ValidationErrorJson(c, verr)
+			return
+		}
+
+		utils.BadRequestErrorJson(c, err.Error())
+		return
+	}
+
+	if input.Quantity == 0 {
+		input.Quantity = 1
+	}
+
+	var product models.Product
+	if err := database.GetDB().First(&product, input.ProductID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Product not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	item, err := services.AddWishlistItem(database.GetDB(), wishlist.ID, product, input.Quantity)
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	item.Product = product
+	utils.JSONResponse(c, http.StatusCreated, item)
+}
+
+func DeleteWishlistItem(c *gin.Context) {
+	item, ok := findCustomerWishlistItem(c)
+	if !ok {
+		return
+	}
+
+	if err := database.GetDB().Unscoped().Delete(&item).Error; err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{"message": "Wishlist item deleted successfully"})
+}
+
+func MoveWishlistItemToCart(c *gin.Context) {
+	item, ok := findCustomerWishlistItem(c)
+	if !ok {
+		return
+	}
+
+	cart, ok := findActiveCart(c, true)
+	if !ok {
+		return
+	}
+
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		return services.MoveWishlistItemToCart(tx, item, cart.ID)
+	}); err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	respondWithPricedCart(c, &cart, http.StatusOK)
+}
+
+func SaveCartItemForLater(c *gin.Context) {
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return
+	}
+
+	var input struct {
+		WishlistID uint `json:"wishlist_id" binding:"omitempty"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			var verr validator.ValidationErrors
+			if errors.As(err, &verr) {
+				utils.ValidationErrorJson(c, verr)
+				return
+			}
+
+			utils.BadRequestErrorJson(c, err.Error())
+			return
+		}
+	}
+
+	cart, ok := findActiveCart(c, false)
+	if !ok {
+		return
+	}
+
+	var cartItem models.CartItem
+	if err := database.GetDB().Where("cart_id = ?", cart.ID).First(&cartItem, c.Param("cartItemId")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Cart item not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	var wishlist models.Wishlist
+	if input.WishlistID != 0 {
+		wishlist, ok = findCustomerWishlist(c, input.WishlistID)
+		if !ok {
+			return
+		}
+	}
+
+	var item models.WishlistItem
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if wishlist.ID == 0 {
+			var err error
+			if wishlist, err = services.SaveForLaterWishlist(tx, customerId.(uint)); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		item, err = services.SaveCartItemForLater(tx, cartItem, wishlist.ID)
+		return err
+	})
+	if err != nil {
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusCreated, item)
+}
+
+func GetSharedWishlist(c *gin.Context) {
+	var wishlist models.Wishlist
+	if err := database.GetDB().Preload("Items.Product").Where("share_token = ? AND is_public = ?", c.Param("token"), true).First(&wishlist).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Wishlist not found")
+			return
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return
+	}
+
+	utils.JSONResponse(c, http.StatusOK, gin.H{
+		"name":  wishlist.Name,
+		"items": wishlist.Items,
+	})
+}
+
+func findCustomerWishlist(c *gin.Context, id interface{}) (models.Wishlist, bool) {
+	var wishlist models.Wishlist
+
+	customerId, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedRequestJson(c, "Customer is not authenticated")
+		return wishlist, false
+	}
+
+	if err := database.GetDB().Preload("Items.Product").Where("customer_id = ?", customerId).First(&wishlist, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Wishlist not found")
+			return wishlist, false
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return wishlist, false
+	}
+
+	return wishlist, true
+}
+
+func findCustomerWishlistItem(c *gin.Context) (models.WishlistItem, bool) {
+	var item models.WishlistItem
+
+	wishlist, ok := findCustomerWishlist(c, c.Param("id"))
+	if !ok {
+		return item, false
+	}
+
+	if err := database.GetDB().Where("wishlist_id = ?", wishlist.ID).First(&item, c.Param("itemId")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundRequestErrorJson(c, "Wishlist item not found")
+			return item, false
+		}
+
+		utils.InternalServerErrorJSON(c, err.Error())
+		return item, false
+	}
+
+	return item, true
+}
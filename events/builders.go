@@ -0,0 +1,108 @@
+package events
+
+import (
+	"api/models"
+	"api/utils"
+	"gorm.io/gorm"
+)
+
+func NewOrderItem(item models.CartItem) OrderItemV1 {
+	orderItem := OrderItemV1{
+		ID:         item.ID,
+		SubOrderID: item.SubOrderID,
+		ProductID:  item.ProductID,
+		Quantity:   item.Quantity,
+		UnitPrice:  item.UnitPrice,
+		TaxAmount:  item.TaxAmount,
+		Status:     item.Status,
+	}
+	if item.Product != nil {
+		orderItem.SellerID = item.Product.SellerId
+		orderItem.SKU = item.Product.SKU
+		orderItem.Name = item.Product.Name
+	}
+
+	return orderItem
+}
+
+func NewSubOrder(subOrder models.SubOrder) SubOrderV1 {
+	return SubOrderV1{
+		ID:             subOrder.ID,
+		SellerID:       subOrder.SellerID,
+		Status:         subOrder.Status,
+		Subtotal:       subOrder.Subtotal,
+		ShippingMethod: subOrder.ShippingMethod,
+		ShippingCost:   subOrder.ShippingCost,
+		DiscountAmount: subOrder.DiscountAmount,
+		TaxAmount:      subOrder.TaxAmount,
+	}
+}
+
+func NewProduct(product models.Product) ProductV1 {
+	return ProductV1{
+		ID:          product.ID,
+		SellerID:    product.SellerId,
+		SKU:         product.SKU,
+		Name:        product.Name,
+		Description: product.Description,
+		Category:    product.Category,
+		Price:       product.Price,
+		TaxClass:    product.TaxClass,
+		Status:      product.Status,
+	}
+}
+
+// NewOrderPlaced builds the order.placed payload and the sellers it
+// concerns.
+func NewOrderPlaced(order models.Order, customerID uint, subOrders []models.SubOrder, items []models.CartItem) (OrderPlacedV1, []uint) {
+	payload := OrderPlacedV1{
+		OrderID:        order.ID,
+		CustomerID:     customerID,
+		Subtotal:       order.Subtotal,
+		ShippingCost:   order.ShippingCost,
+		DiscountAmount: order.DiscountAmount,
+		TaxAmount:      order.TaxAmount,
+		TotalAmount:    order.TotalAmount,
+	}
+
+	var sellerIDs []uint
+	for _, subOrder := range subOrders {
+		payload.SubOrders = append(payload.SubOrders, NewSubOrder(subOrder))
+		sellerIDs = append(sellerIDs, subOrder.SellerID)
+	}
+	for _, item := range items {
+		payload.Items = append(payload.Items, NewOrderItem(item))
+	}
+
+	return payload, sellerIDs
+}
+
+// RecordOrderItemStatusChange records order_item.status_changed and, for
+// cancellations, order_item.cancelled. item must have its Product loaded.
+func RecordOrderItemStatusChange(tx *gorm.DB, orderID uint, item models.CartItem, previousStatus string) error {
+	if item.Status == previousStatus {
+		return nil
+	}
+
+	orderItem := NewOrderItem(item)
+	if err := Record(tx, "order", orderID, OrderItemStatusChangedV1{OrderID: orderID, Item: orderItem, PreviousStatus: previousStatus}, orderItem.SellerID); err != nil {
+		return err
+	}
+
+	if item.Status != utils.StatusCancelled {
+		return nil
+	}
+
+	return Record(tx, "order", orderID, OrderItemCancelledV1{OrderID: orderID, Item: orderItem, PreviousStatus: previousStatus}, orderItem.SellerID)
+}
+
+// RecordSubOrderStatusChange records sub_order.status_changed when the
+// status actually changed.
+func RecordSubOrderStatusChange(tx *gorm.DB, subOrder models.SubOrder, previousStatus string) error {
+	if subOrder.Status == previousStatus {
+		return nil
+	}
+
+	changed := SubOrderStatusChangedV1{OrderID: subOrder.OrderID, SubOrder: NewSubOrder(subOrder), PreviousStatus: previousStatus}
+	return Record(tx, "order", subOrder.OrderID, changed, subOrder.SellerID)
+}
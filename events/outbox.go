@@ -0,0 +1,134 @@
+package events
+
+import (
+	"api/models"
+	"api/utils"
+	"encoding/json"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is the envelope published to sinks.
+type Event struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uint            `json:"aggregate_id"`
+	SellerIDs     []uint          `json:"seller_ids,omitempty"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Record writes the event to the outbox. It must be called with the
+// transaction of the change it describes, so the event exists if and only
+// if the change commits.
+func Record(tx *gorm.DB, aggregateType string, aggregateID uint, payload Payload, sellerIDs ...uint) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	eventID, err := utils.RandomToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return tx.Create(&models.OutboxEvent{
+		EventID:       eventID,
+		Type:          payload.EventType(),
+		Version:       payload.SchemaVersion(),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		SellerIDs:     joinIDs(uniqueIDs(sellerIDs)),
+		Payload:       string(data),
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	}).Error
+}
+
+func FromOutbox(row models.OutboxEvent) Event {
+	return Event{
+		ID:            row.EventID,
+		Type:          row.Type,
+		Version:       row.Version,
+		AggregateType: row.AggregateType,
+		AggregateID:   row.AggregateID,
+		SellerIDs:     splitIDs(row.SellerIDs),
+		OccurredAt:    row.OccurredAt,
+		Data:          json.RawMessage(row.Payload),
+	}
+}
+
+// ForSeller narrows the event to what one seller may see: order.placed
+// keeps only that seller's sub-order and items.
+func (e Event) ForSeller(sellerID uint) (Event, error) {
+	e.SellerIDs = []uint{sellerID}
+	if e.Type != TypeOrderPlaced || e.Version != 1 {
+		return e, nil
+	}
+
+	var placed OrderPlacedV1
+	if err := json.Unmarshal(e.Data, &placed); err != nil {
+		return e, err
+	}
+
+	subOrders := placed.SubOrders[:0]
+	for _, subOrder := range placed.SubOrders {
+		if subOrder.SellerID == sellerID {
+			subOrders = append(subOrders, subOrder)
+		}
+	}
+	items := placed.Items[:0]
+	for _, item := range placed.Items {
+		if item.SellerID == sellerID {
+			items = append(items, item)
+		}
+	}
+	placed.SubOrders = subOrders
+	placed.Items = items
+
+	data, err := json.Marshal(placed)
+	if err != nil {
+		return e, err
+	}
+	e.Data = data
+
+	return e, nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := map[uint]bool{}
+	var unique []uint
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
+
+func joinIDs(ids []uint) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(uint64(id), 10)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func splitIDs(value string) []uint {
+	var ids []uint
+	for _, part := range strings.Split(value, ",") {
+		if id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,262 @@
+package events
+
+import (
+	"api/models"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	relayBatchSize    = 100
+	relayPollInterval = time.Second
+	// relayLease is how long claimed events stay hidden from other relays
+	// while they are published outside of any transaction.
+	relayLease = 10 * time.Minute
+)
+
+// Relay publishes outbox events to its sinks in insertion order per
+// aggregate. Each sink is tracked separately, so one that is down only
+// delays its own deliveries. Events are delivered at least once: a crash
+// between publishing and recording it resends the event, so consumers
+// should dedupe on the event ID.
+type Relay struct {
+	db    *gorm.DB
+	sinks map[string]Sink
+	names []string
+}
+
+// NewRelay publishes to the named sinks, skipping nil ones. Names are stored
+// with each event, so keep them stable across deploys.
+func NewRelay(db *gorm.DB, sinks map[string]Sink) *Relay {
+	relay := &Relay{db: db, sinks: map[string]Sink{}}
+	for name, sink := range sinks {
+		if sink != nil {
+			relay.sinks[name] = sink
+			relay.names = append(relay.names, name)
+		}
+	}
+	sort.Strings(relay.names)
+
+	return relay
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	if len(r.sinks) == 0 {
+		log.Println("Event relay is disabled")
+		return
+	}
+
+	for {
+		published, err := r.publishBatch(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Event relay failed: %v", err)
+		}
+
+		if published == relayBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(relayPollInterval):
+		}
+	}
+}
+
+// publishBatch claims due events in a short transaction, publishes them
+// with no transaction or row lock held, and records the outcome in a second
+// short transaction. Events whose lease runs out before they are reached are
+// released untouched.
+func (r *Relay) publishBatch(ctx context.Context) (int, error) {
+	rows, blockers, leaseUntil, err := r.claim()
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+
+	published := 0
+	for i := range rows {
+		if ctx.Err() != nil || time.Now().After(leaseUntil.Add(-time.Minute)) {
+			rows[i].NextAttemptAt = time.Now()
+			continue
+		}
+
+		if r.publishRow(ctx, &rows[i], blockers) {
+			publishedAt := time.Now()
+			rows[i].PublishedAt = &publishedAt
+			published++
+		}
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			if err := tx.Model(&row).
+				Select("published_at", "published_sinks", "attempts", "last_error", "next_attempt_at").
+				Updates(&row).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return published, err
+}
+
+// claim leases the next due events by pushing their next attempt past the
+// lease, so other relays skip them until this one reports back or dies.
+func (r *Relay) claim() ([]models.OutboxEvent, map[string]models.OutboxEvent, time.Time, error) {
+	var rows []models.OutboxEvent
+	var blockers map[string]models.OutboxEvent
+	now := time.Now()
+	leaseUntil := now.Add(relayLease)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", now).
+			Order("id").
+			Limit(relayBatchSize).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+
+		if len(rows) == 0 {
+			return nil
+		}
+
+		var err error
+		if blockers, err = r.backingOff(tx, rows, now); err != nil {
+			return err
+		}
+
+		var ids []uint
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", leaseUntil).Error
+	})
+
+	return rows, blockers, leaseUntil, err
+}
+
+// publishRow sends the event to every sink that has not received it yet
+// and reports whether all of them now have. Events of an aggregate are
+// published in order, so a sink skips the event while an earlier one of
+// the same aggregate is still being retried for it.
+func (r *Relay) publishRow(ctx context.Context, row *models.OutboxEvent, blockers map[string]models.OutboxEvent) bool {
+	done := publishedSinks(*row)
+	var failures []string
+	var waitUntil time.Time
+
+	for _, name := range r.names {
+		if done[name] {
+			continue
+		}
+
+		key := name + ":" + aggregateKey(*row)
+		if blocker, ok := blockers[key]; ok && blocker.ID < row.ID {
+			if waitUntil.IsZero() || blocker.NextAttemptAt.Before(waitUntil) {
+				waitUntil = blocker.NextAttemptAt
+			}
+			continue
+		}
+
+		if err := r.sinks[name].Publish(ctx, FromOutbox(*row)); err != nil {
+			failures = append(failures, name+": "+err.Error())
+			continue
+		}
+
+		done[name] = true
+	}
+
+	var names []string
+	for name := range done {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	row.PublishedSinks = strings.Join(names, ",")
+
+	if len(failures) > 0 {
+		row.Attempts++
+		row.LastError = strings.Join(failures, "; ")
+		row.NextAttemptAt = time.Now().Add(relayBackoff(row.Attempts))
+
+		for _, name := range r.names {
+			if !done[name] {
+				key := name + ":" + aggregateKey(*row)
+				if _, ok := blockers[key]; !ok {
+					blockers[key] = *row
+				}
+			}
+		}
+
+		return false
+	}
+
+	if !waitUntil.IsZero() {
+		row.NextAttemptAt = waitUntil
+		return false
+	}
+
+	row.LastError = ""
+	return true
+}
+
+// backingOff returns, per sink and aggregate in rows, the earliest event
+// that is waiting for its next attempt, or leased by another relay, and has
+// not reached that sink.
+func (r *Relay) backingOff(tx *gorm.DB, rows []models.OutboxEvent, now time.Time) (map[string]models.OutboxEvent, error) {
+	var aggregates [][]interface{}
+	for _, row := range rows {
+		aggregates = append(aggregates, []interface{}{row.AggregateType, row.AggregateID})
+	}
+
+	var waiting []models.OutboxEvent
+	if err := tx.Where("published_at IS NULL AND next_attempt_at > ? AND id < ?", now, rows[len(rows)-1].ID).
+		Where("(aggregate_type, aggregate_id) IN ?", aggregates).
+		Order("id").
+		Find(&waiting).Error; err != nil {
+		return nil, err
+	}
+
+	blockers := map[string]models.OutboxEvent{}
+	for _, event := range waiting {
+		done := publishedSinks(event)
+		for _, name := range r.names {
+			key := name + ":" + aggregateKey(event)
+			if _, ok := blockers[key]; !ok && !done[name] {
+				blockers[key] = event
+			}
+		}
+	}
+
+	return blockers, nil
+}
+
+func publishedSinks(event models.OutboxEvent) map[string]bool {
+	done := map[string]bool{}
+	for _, name := range strings.Split(event.PublishedSinks, ",") {
+		if name != "" {
+			done[name] = true
+		}
+	}
+
+	return done
+}
+
+func aggregateKey(event models.OutboxEvent) string {
+	return fmt.Sprintf("%s/%d", event.AggregateType, event.AggregateID)
+}
+
+func relayBackoff(attempt int) time.Duration {
+	return time.Duration(math.Min(float64(time.Second)*math.Pow(2, float64(attempt)), float64(10*time.Minute)))
+}
@@ -0,0 +1,168 @@
+package events
+
+import (
+	"api/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	err       error
+	published []string
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event Event) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+func outboxRow(id uint, eventID string, aggregateID uint) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:            id,
+		EventID:       eventID,
+		Type:          TypeOrderPlaced,
+		AggregateType: "order",
+		AggregateID:   aggregateID,
+		Payload:       "{}",
+		NextAttemptAt: time.Now(),
+	}
+}
+
+func TestRelayBackoff(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{5, 32 * time.Second},
+		{9, 512 * time.Second},
+		{10, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+
+	for _, c := range cases {
+		if got := relayBackoff(c.attempt); got != c.want {
+			t.Errorf("relayBackoff(%d) = %s, want %s", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestPublishedSinks(t *testing.T) {
+	done := publishedSinks(models.OutboxEvent{PublishedSinks: "webhooks,,notifications"})
+	if len(done) != 2 || !done["webhooks"] || !done["notifications"] {
+		t.Errorf("publishedSinks = %v", done)
+	}
+
+	if done := publishedSinks(models.OutboxEvent{}); len(done) != 0 {
+		t.Errorf("publishedSinks of a new event = %v, want none", done)
+	}
+}
+
+func TestPublishRowToAllSinks(t *testing.T) {
+	webhooks, notifications := &recordingSink{}, &recordingSink{}
+	relay := NewRelay(nil, map[string]Sink{"webhooks": webhooks, "notifications": notifications, "disabled": nil})
+
+	row := outboxRow(1, "e1", 10)
+	row.LastError = "earlier failure"
+	if !relay.publishRow(context.Background(), &row, map[string]models.OutboxEvent{}) {
+		t.Fatal("publishRow reported the event as not fully published")
+	}
+
+	if row.PublishedSinks != "notifications,webhooks" {
+		t.Errorf("published sinks = %q", row.PublishedSinks)
+	}
+	if row.LastError != "" {
+		t.Errorf("last error = %q, want it cleared", row.LastError)
+	}
+	if len(webhooks.published) != 1 || len(notifications.published) != 1 {
+		t.Errorf("sinks received %v and %v", webhooks.published, notifications.published)
+	}
+}
+
+func TestPublishRowSkipsSinksThatHaveTheEvent(t *testing.T) {
+	webhooks, notifications := &recordingSink{}, &recordingSink{}
+	relay := NewRelay(nil, map[string]Sink{"webhooks": webhooks, "notifications": notifications})
+
+	row := outboxRow(1, "e1", 10)
+	row.PublishedSinks = "webhooks"
+	if !relay.publishRow(context.Background(), &row, map[string]models.OutboxEvent{}) {
+		t.Fatal("publishRow reported the event as not fully published")
+	}
+
+	if len(webhooks.published) != 0 {
+		t.Errorf("webhooks received the event again: %v", webhooks.published)
+	}
+	if len(notifications.published) != 1 {
+		t.Errorf("notifications received %v", notifications.published)
+	}
+}
+
+func TestPublishRowHoldsBackLaterEventsOfTheAggregate(t *testing.T) {
+	webhooks := &recordingSink{err: errors.New("endpoint down")}
+	notifications := &recordingSink{}
+	relay := NewRelay(nil, map[string]Sink{"webhooks": webhooks, "notifications": notifications})
+	blockers := map[string]models.OutboxEvent{}
+
+	first := outboxRow(1, "e1", 10)
+	if relay.publishRow(context.Background(), &first, blockers) {
+		t.Fatal("an event a sink failed on was reported as published")
+	}
+	if first.Attempts != 1 || first.LastError != "webhooks: endpoint down" {
+		t.Errorf("failed row = %+v", first)
+	}
+	if first.PublishedSinks != "notifications" {
+		t.Errorf("published sinks = %q, want only the sink that succeeded", first.PublishedSinks)
+	}
+	if !first.NextAttemptAt.After(time.Now()) {
+		t.Errorf("next attempt %s is not in the future", first.NextAttemptAt)
+	}
+
+	// The sink recovers, but the second event of the aggregate must still
+	// wait for the first one to reach it.
+	webhooks.err = nil
+	second := outboxRow(2, "e2", 10)
+	if relay.publishRow(context.Background(), &second, blockers) {
+		t.Fatal("an event behind a failed one was reported as published")
+	}
+	if len(webhooks.published) != 0 {
+		t.Errorf("webhooks received %v ahead of the failed event", webhooks.published)
+	}
+	if second.PublishedSinks != "notifications" {
+		t.Errorf("published sinks = %q, want the unblocked sink only", second.PublishedSinks)
+	}
+	if !second.NextAttemptAt.Equal(first.NextAttemptAt) {
+		t.Errorf("blocked event retries at %s, want with the blocker at %s", second.NextAttemptAt, first.NextAttemptAt)
+	}
+	if second.Attempts != 0 {
+		t.Errorf("blocked event counted %d attempts", second.Attempts)
+	}
+
+	other := outboxRow(3, "e3", 11)
+	if !relay.publishRow(context.Background(), &other, blockers) {
+		t.Error("an event of another aggregate was held back")
+	}
+	if len(webhooks.published) != 1 || webhooks.published[0] != "e3" {
+		t.Errorf("webhooks received %v, want only e3", webhooks.published)
+	}
+}
+
+func TestPublishRowIgnoresLaterBlockers(t *testing.T) {
+	webhooks := &recordingSink{}
+	relay := NewRelay(nil, map[string]Sink{"webhooks": webhooks})
+
+	later := outboxRow(5, "e5", 10)
+	later.NextAttemptAt = time.Now().Add(time.Minute)
+	blockers := map[string]models.OutboxEvent{"webhooks:" + aggregateKey(later): later}
+
+	earlier := outboxRow(4, "e4", 10)
+	if !relay.publishRow(context.Background(), &earlier, blockers) {
+		t.Error("an event was held back by a later event of its aggregate")
+	}
+}
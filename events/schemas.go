@@ -0,0 +1,120 @@
+package events
+
+// Payloads are versioned. A breaking change to a payload adds a new struct
+// (OrderPlacedV2, ...) with a bumped SchemaVersion instead of changing the
+// existing one, so consumers can keep decoding the versions they know.
+
+const (
+	TypeOrderPlaced            = "order.placed"
+	TypeOrderItemStatusChanged = "order_item.status_changed"
+	TypeOrderItemCancelled     = "order_item.cancelled"
+	TypeSubOrderStatusChanged  = "sub_order.status_changed"
+	TypeProductCreated         = "product.created"
+	TypeProductUpdated         = "product.updated"
+	TypeProductDeleted         = "product.deleted"
+)
+
+type Payload interface {
+	EventType() string
+	SchemaVersion() int
+}
+
+type OrderItemV1 struct {
+	ID         uint    `json:"id"`
+	SubOrderID *uint   `json:"sub_order_id"`
+	ProductID  uint    `json:"product_id"`
+	SellerID   uint    `json:"seller_id"`
+	SKU        string  `json:"sku"`
+	Name       string  `json:"name"`
+	Quantity   int     `json:"quantity"`
+	UnitPrice  float64 `json:"unit_price"`
+	TaxAmount  float64 `json:"tax_amount"`
+	Status     string  `json:"status"`
+}
+
+type SubOrderV1 struct {
+	ID             uint    `json:"id"`
+	SellerID       uint    `json:"seller_id"`
+	Status         string  `json:"status"`
+	Subtotal       float64 `json:"subtotal"`
+	ShippingMethod string  `json:"shipping_method"`
+	ShippingCost   float64 `json:"shipping_cost"`
+	DiscountAmount float64 `json:"discount_amount"`
+	TaxAmount      float64 `json:"tax_amount"`
+}
+
+type OrderPlacedV1 struct {
+	OrderID        uint          `json:"order_id"`
+	CustomerID     uint          `json:"customer_id"`
+	Subtotal       float64       `json:"subtotal"`
+	ShippingCost   float64       `json:"shipping_cost"`
+	DiscountAmount float64       `json:"discount_amount"`
+	TaxAmount      float64       `json:"tax_amount"`
+	TotalAmount    float64       `json:"total_amount"`
+	SubOrders      []SubOrderV1  `json:"sub_orders"`
+	Items          []OrderItemV1 `json:"items"`
+}
+
+func (OrderPlacedV1) EventType() string  { return TypeOrderPlaced }
+func (OrderPlacedV1) SchemaVersion() int { return 1 }
+
+type OrderItemStatusChangedV1 struct {
+	OrderID        uint        `json:"order_id"`
+	Item           OrderItemV1 `json:"item"`
+	PreviousStatus string      `json:"previous_status"`
+}
+
+func (OrderItemStatusChangedV1) EventType() string  { return TypeOrderItemStatusChanged }
+func (OrderItemStatusChangedV1) SchemaVersion() int { return 1 }
+
+type OrderItemCancelledV1 struct {
+	OrderID        uint        `json:"order_id"`
+	Item           OrderItemV1 `json:"item"`
+	PreviousStatus string      `json:"previous_status"`
+}
+
+func (OrderItemCancelledV1) EventType() string  { return TypeOrderItemCancelled }
+func (OrderItemCancelledV1) SchemaVersion() int { return 1 }
+
+type SubOrderStatusChangedV1 struct {
+	OrderID        uint       `json:"order_id"`
+	SubOrder       SubOrderV1 `json:"sub_order"`
+	PreviousStatus string     `json:"previous_status"`
+}
+
+func (SubOrderStatusChangedV1) EventType() string  { return TypeSubOrderStatusChanged }
+func (SubOrderStatusChangedV1) SchemaVersion() int { return 1 }
+
+type ProductV1 struct {
+	ID          uint    `json:"id"`
+	SellerID    uint    `json:"seller_id"`
+	SKU         string  `json:"sku"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Category    string  `json:"category"`
+	Price       float64 `json:"price"`
+	TaxClass    string  `json:"tax_class"`
+	Status      string  `json:"status"`
+}
+
+type ProductCreatedV1 struct {
+	Product ProductV1 `json:"product"`
+}
+
+func (ProductCreatedV1) EventType() string  { return TypeProductCreated }
+func (ProductCreatedV1) SchemaVersion() int { return 1 }
+
+type ProductUpdatedV1 struct {
+	Product       ProductV1 `json:"product"`
+	PreviousPrice float64   `json:"previous_price"`
+}
+
+func (ProductUpdatedV1) EventType() string  { return TypeProductUpdated }
+func (ProductUpdatedV1) SchemaVersion() int { return 1 }
+
+type ProductDeletedV1 struct {
+	Product ProductV1 `json:"product"`
+}
+
+func (ProductDeletedV1) EventType() string  { return TypeProductDeleted }
+func (ProductDeletedV1) SchemaVersion() int { return 1 }
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+type Sink interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// SinkFromEnv builds the sink selected by EVENT_SINK: "log" (default),
+// "http" (posting to EVENT_SINK_URL, signed with EVENT_SINK_SECRET when
+// set) or "none". NATS and Kafka sinks need a client and are wired in with
+// NewNATSSink / NewKafkaSink instead.
+func SinkFromEnv() Sink {
+	switch os.Getenv("EVENT_SINK") {
+	case "none":
+		return nil
+	case "http":
+		return NewHTTPSink(os.Getenv("EVENT_SINK_URL"), os.Getenv("EVENT_SINK_SECRET"))
+	default:
+		return LogSink{}
+	}
+}
+
+type LogSink struct{}
+
+func (LogSink) Publish(ctx context.Context, event Event) error {
+	log.Printf("event %s %s v%d %s/%d: %s", event.ID, event.Type, event.Version, event.AggregateType, event.AggregateID, event.Data)
+	return nil
+}
+
+type HTTPSink struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func NewHTTPSink(url string, secret string) *HTTPSink {
+	return &HTTPSink{URL: url, Secret: secret, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *HTTPSink) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Event-Id", event.ID)
+	request.Header.Set("X-Event-Type", event.Type)
+	request.Header.Set("X-Event-Version", strconv.Itoa(event.Version))
+	if s.Secret != "" {
+		request.Header.Set("X-Signature", Sign(s.Secret, body))
+	}
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("event sink responded with %s", response.Status)
+	}
+
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body, prefixed with the algorithm.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NATSPublisher is the subset of a NATS connection the sink needs, so the
+// module does not depend on a NATS client.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+type NATSSink struct {
+	Conn          NATSPublisher
+	SubjectPrefix string
+}
+
+func NewNATSSink(conn NATSPublisher, subjectPrefix string) *NATSSink {
+	return &NATSSink{Conn: conn, SubjectPrefix: subjectPrefix}
+}
+
+func (s *NATSSink) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return s.Conn.Publish(s.SubjectPrefix+event.Type, body)
+}
+
+// KafkaProducer is the subset of a Kafka producer the sink needs.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key []byte, value []byte) error
+}
+
+type KafkaSink struct {
+	Producer KafkaProducer
+	Topic    string
+}
+
+func NewKafkaSink(producer KafkaProducer, topic string) *KafkaSink {
+	return &KafkaSink{Producer: producer, Topic: topic}
+}
+
+// Publish keys messages by aggregate so events of one order or product
+// stay in order within a partition.
+func (s *KafkaSink) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s:%d", event.AggregateType, event.AggregateID)
+	return s.Producer.Produce(ctx, s.Topic, []byte(key), body)
+}
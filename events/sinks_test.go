@@ -0,0 +1,88 @@
+package events
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// Known HMAC-SHA256 vector from RFC 4231, test case 2.
+	got := Sign("Jefe", []byte("what do ya want for nothing?"))
+	want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestHTTPSinkSignsTheBodyItSends(t *testing.T) {
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	event := Event{
+		ID:            "e1",
+		Type:          TypeOrderPlaced,
+		Version:       1,
+		AggregateType: "order",
+		AggregateID:   10,
+		OccurredAt:    time.Now(),
+		Data:          json.RawMessage(`{"total":12.5}`),
+	}
+	if err := NewHTTPSink(server.URL, "secret").Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	// Verify the way a receiver would: HMAC the bytes that arrived.
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := header.Get("X-Signature"); got != want {
+		t.Errorf("X-Signature = %q, want %q", got, want)
+	}
+	if header.Get("X-Event-Id") != "e1" || header.Get("X-Event-Type") != TypeOrderPlaced || header.Get("X-Event-Version") != "1" {
+		t.Errorf("event headers = %v", header)
+	}
+
+	var received Event
+	if err := json.Unmarshal(body, &received); err != nil || received.ID != "e1" {
+		t.Errorf("received body %s", body)
+	}
+}
+
+func TestHTTPSinkWithoutSecretDoesNotSign(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	if err := NewHTTPSink(server.URL, "").Publish(context.Background(), Event{ID: "e1"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if header.Get("X-Signature") != "" {
+		t.Errorf("unsigned sink sent X-Signature %q", header.Get("X-Signature"))
+	}
+}
+
+func TestHTTPSinkFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	if err := NewHTTPSink(server.URL, "secret").Publish(context.Background(), Event{ID: "e1"}); err == nil {
+		t.Error("Publish succeeded against a 502 response")
+	}
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"api/services"
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// RegisterCustomerJobs schedules the abandoned cart jobs. The interval and
+// thresholds come from ABANDONED_CART_CHECK_INTERVAL (default 1h),
+// ABANDONED_CART_REMIND_AFTER (default 24h) and ABANDONED_CART_EXPIRE_AFTER
+// (default 720h). Reminders are sent as customer notifications.
+func RegisterCustomerJobs(scheduler *Scheduler, db *gorm.DB) {
+	services.SetCartReminderNotifier(NewCartReminderNotifier(db))
+
+	interval := durationFromEnv("ABANDONED_CART_CHECK_INTERVAL", time.Hour)
+	remindAfter := durationFromEnv("ABANDONED_CART_REMIND_AFTER", 24*time.Hour)
+	expireAfter := durationFromEnv("ABANDONED_CART_EXPIRE_AFTER", 30*24*time.Hour)
+
+	scheduler.Every("abandoned-cart-reminders", interval, func(ctx context.Context) error {
+		reminded, err := services.RemindAbandonedCarts(db.WithContext(ctx), remindAfter, time.Now())
+		if reminded > 0 {
+			log.Printf("Sent %d abandoned cart reminders", reminded)
+		}
+		return err
+	})
+
+	scheduler.Every("abandoned-cart-expiry", interval, func(ctx context.Context) error {
+		expired, err := services.ExpireAbandonedCarts(db.WithContext(ctx), expireAfter, time.Now())
+		if expired > 0 {
+			log.Printf("Expired %d abandoned carts", expired)
+		}
+		return err
+	})
+}
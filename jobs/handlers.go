@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"api/models"
+	"api/services"
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+)
+
+const TypeProductImport = "product_import"
+
+type ProductImportPayload struct {
+	ImportID uint `json:"import_id"`
+}
+
+func init() {
+	RegisterHandler(TypeProductImport, func(ctx context.Context, db *gorm.DB, job models.Job) error {
+		var payload ProductImportPayload
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return err
+		}
+
+		return services.RunProductImport(db, payload.ImportID)
+	})
+}
@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"api/services"
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// RegisterMaintenanceJobs schedules the purge of soft-deleted records. It
+// runs every SOFT_DELETE_PURGE_INTERVAL (default 24h) and removes records
+// deleted more than SOFT_DELETE_RETENTION (default 2160h) ago.
+func RegisterMaintenanceJobs(scheduler *Scheduler, db *gorm.DB) {
+	interval := durationFromEnv("SOFT_DELETE_PURGE_INTERVAL", 24*time.Hour)
+	retention := durationFromEnv("SOFT_DELETE_RETENTION", 90*24*time.Hour)
+
+	scheduler.Every("soft-delete-purge", interval, func(ctx context.Context) error {
+		purged, err := services.PurgeDeleted(db.WithContext(ctx), retention, time.Now())
+		for table, count := range purged {
+			log.Printf("Purged %d deleted %s", count, table)
+		}
+		return err
+	})
+}
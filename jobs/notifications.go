@@ -0,0 +1,115 @@
+package jobs
+
+import (
+	"api/events"
+	"api/models"
+	"api/notifications"
+	"api/services"
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+	"log"
+)
+
+const TypeNotification = "notification"
+
+func init() {
+	RegisterHandler(TypeNotification, func(ctx context.Context, db *gorm.DB, job models.Job) error {
+		var request notifications.Request
+		if err := json.Unmarshal([]byte(job.Payload), &request); err != nil {
+			return err
+		}
+
+		return notifications.Send(ctx, db, request)
+	})
+}
+
+// EnqueueNotifications queues one job per request so a failing channel is
+// retried on its own without resending the others.
+func EnqueueNotifications(db *gorm.DB, requests []notifications.Request) error {
+	for _, request := range requests {
+		if _, err := Enqueue(db, TypeNotification, request, EnqueueOptions{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NotificationSink turns published order events into notification jobs.
+type NotificationSink struct {
+	db *gorm.DB
+}
+
+func NewNotificationSink(db *gorm.DB) *NotificationSink {
+	return &NotificationSink{db: db}
+}
+
+func (s *NotificationSink) Publish(ctx context.Context, event events.Event) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		requests, err := notifications.FromEvent(tx, event)
+		if err != nil {
+			return err
+		}
+
+		return EnqueueNotifications(tx, requests)
+	})
+}
+
+// CartReminderNotifier sends abandoned cart reminders through the
+// customer's notification channels.
+type CartReminderNotifier struct {
+	db *gorm.DB
+}
+
+func NewCartReminderNotifier(db *gorm.DB) *CartReminderNotifier {
+	return &CartReminderNotifier{db: db}
+}
+
+func (n *CartReminderNotifier) NotifyAbandonedCart(reminder services.AbandonedCartReminder) error {
+	var items []map[string]interface{}
+	for _, item := range reminder.Items {
+		name := ""
+		if item.Product != nil {
+			name = item.Product.Name
+		}
+		items = append(items, map[string]interface{}{"name": name, "quantity": item.Quantity})
+	}
+
+	requests, err := notifications.Requests(n.db, "customer", reminder.Customer.ID, notifications.KindCartReminder, map[string]interface{}{
+		"cart_id":    reminder.Cart.ID,
+		"item_count": len(reminder.Items),
+		"items":      items,
+	})
+	if err != nil {
+		return err
+	}
+
+	return EnqueueNotifications(n.db, requests)
+}
+
+// RegisterPriceDropNotifications tells customers when a product on one of
+// their wishlists gets cheaper. It must run in every process that changes
+// product prices.
+func RegisterPriceDropNotifications(db *gorm.DB) {
+	services.RegisterPriceDropHook(func(drop services.PriceDrop) {
+		if err := NotifyPriceDrop(db, drop); err != nil {
+			log.Printf("Could not queue price drop notification for wishlist item %d: %v", drop.Item.ID, err)
+		}
+	})
+}
+
+func NotifyPriceDrop(db *gorm.DB, drop services.PriceDrop) error {
+	requests, err := notifications.Requests(db, "customer", drop.Wishlist.CustomerID, notifications.KindPriceDrop, map[string]interface{}{
+		"product_id":    drop.Product.ID,
+		"product_name":  drop.Product.Name,
+		"wishlist_name": drop.Wishlist.Name,
+		"old_price":     drop.OldPrice,
+		"price":         drop.Product.Price,
+	})
+	if err != nil {
+		return err
+	}
+
+	return EnqueueNotifications(db, requests)
+}
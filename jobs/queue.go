@@ -0,0 +1,325 @@
+package jobs
+
+import (
+	"api/models"
+	"api/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultQueue       = "default"
+	DefaultMaxAttempts = 5
+)
+
+var ErrJobNotRetryable = errors.New("only dead jobs can be retried")
+
+// Handler processes one job. Returning an error schedules a retry with
+// backoff until the job runs out of attempts and is dead-lettered.
+type Handler func(ctx context.Context, db *gorm.DB, job models.Job) error
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[string]Handler{}
+)
+
+func RegisterHandler(jobType string, handler Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+
+	handlers[jobType] = handler
+}
+
+func handlerFor(jobType string) (Handler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+
+	handler, ok := handlers[jobType]
+	return handler, ok
+}
+
+type EnqueueOptions struct {
+	Queue       string
+	RunAt       time.Time
+	MaxAttempts int
+}
+
+// Enqueue stores a job. Pass the caller's transaction so the job only
+// becomes visible if the surrounding work commits.
+func Enqueue(db *gorm.DB, jobType string, payload interface{}, options EnqueueOptions) (models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	if options.Queue == "" {
+		options.Queue = DefaultQueue
+	}
+	if options.RunAt.IsZero() {
+		options.RunAt = time.Now()
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+
+	job := models.Job{
+		Queue:       options.Queue,
+		Type:        jobType,
+		Payload:     string(data),
+		Status:      utils.JobStatusQueued,
+		RunAt:       options.RunAt,
+		MaxAttempts: options.MaxAttempts,
+	}
+	err = db.Create(&job).Error
+
+	return job, err
+}
+
+func RetryJob(db *gorm.DB, id string) (models.Job, error) {
+	var job models.Job
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&job, id).Error; err != nil {
+			return err
+		}
+
+		if job.Status != utils.JobStatusDead {
+			return ErrJobNotRetryable
+		}
+
+		job.Status = utils.JobStatusQueued
+		job.Attempts = 0
+		job.RunAt = time.Now()
+		job.LockedAt = nil
+		job.LockedBy = ""
+
+		return tx.Save(&job).Error
+	})
+
+	return job, err
+}
+
+// Backoff grows exponentially from 10 seconds and is capped at an hour,
+// with up to 20% jitter so failing jobs do not retry in lockstep.
+func Backoff(attempt int) time.Duration {
+	delay := time.Duration(math.Min(float64(10*time.Second)*math.Pow(2, float64(attempt-1)), float64(time.Hour)))
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+
+// minLockTimeout keeps stale-job reclaiming from requeueing jobs that are
+// still running.
+const minLockTimeout = time.Minute
+
+type WorkerPool struct {
+	db           *gorm.DB
+	queue        string
+	concurrency  int
+	pollInterval time.Duration
+	lockTimeout  time.Duration
+	name         string
+}
+
+// NewWorkerPool reads WORKER_QUEUE, WORKER_CONCURRENCY, WORKER_POLL_INTERVAL
+// and WORKER_LOCK_TIMEOUT, defaulting to the default queue, 4 workers, 1s
+// and 15m.
+func NewWorkerPool(db *gorm.DB) *WorkerPool {
+	queue := os.Getenv("WORKER_QUEUE")
+	if queue == "" {
+		queue = DefaultQueue
+	}
+
+	concurrency, err := strconv.Atoi(os.Getenv("WORKER_CONCURRENCY"))
+	if err != nil || concurrency < 1 {
+		concurrency = 4
+	}
+
+	lockTimeout := durationFromEnv("WORKER_LOCK_TIMEOUT", 15*time.Minute)
+	if lockTimeout < minLockTimeout {
+		log.Printf("WORKER_LOCK_TIMEOUT %s is below %s, using 15m", lockTimeout, minLockTimeout)
+		lockTimeout = 15 * time.Minute
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &WorkerPool{
+		db:           db,
+		queue:        queue,
+		concurrency:  concurrency,
+		pollInterval: durationFromEnv("WORKER_POLL_INTERVAL", time.Second),
+		lockTimeout:  lockTimeout,
+		name:         fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+	}
+}
+
+// Run blocks until ctx is cancelled and every worker has finished its
+// current job.
+func (p *WorkerPool) Run(ctx context.Context) {
+	log.Printf("Starting %d workers on queue %s", p.concurrency, p.queue)
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			p.work(ctx, fmt.Sprintf("%s/%d", p.name, worker))
+		}(i)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.reclaimStaleJobs(ctx)
+	}()
+
+	wg.Wait()
+}
+
+func (p *WorkerPool) work(ctx context.Context, worker string) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		job, err := p.claim(worker)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Worker %s could not claim a job: %v", worker, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(p.pollInterval):
+			}
+			continue
+		}
+
+		// A job that was claimed runs to completion even during shutdown.
+		p.execute(context.WithoutCancel(ctx), job)
+	}
+}
+
+// claim locks the next due job with FOR UPDATE SKIP LOCKED so concurrent
+// workers never pick the same row, and marks it running.
+func (p *WorkerPool) claim(worker string) (models.Job, error) {
+	var job models.Job
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("queue = ? AND status = ? AND run_at <= ?", p.queue, utils.JobStatusQueued, time.Now()).
+			Order("run_at").
+			First(&job).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		job.Status = utils.JobStatusRunning
+		job.Attempts++
+		job.LockedAt = &now
+		job.LockedBy = worker
+
+		return tx.Save(&job).Error
+	})
+
+	return job, err
+}
+
+func (p *WorkerPool) execute(ctx context.Context, job models.Job) {
+	handler, ok := handlerFor(job.Type)
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("no handler registered for job type %q", job.Type)
+		job.Attempts = job.MaxAttempts
+	} else {
+		err = runHandler(ctx, handler, p.db, job)
+	}
+
+	worker := job.LockedBy
+	finish(&job, err, time.Now())
+
+	// The job may have been reclaimed and handed to another worker while
+	// this one was slow, in which case the other worker's state wins.
+	result := p.db.Model(&job).Where("status = ? AND locked_by = ?", utils.JobStatusRunning, worker).Select("*").Updates(&job)
+	if result.Error != nil {
+		log.Printf("Could not save job %d: %v", job.ID, result.Error)
+	} else if result.RowsAffected == 0 {
+		log.Printf("Job %d was reclaimed by another worker, discarding the result of %s", job.ID, worker)
+	}
+}
+
+// finish records the outcome of a run: the job completes, is retried with
+// backoff, or is dead-lettered once it is out of attempts.
+func finish(job *models.Job, err error, now time.Time) {
+	job.LockedAt = nil
+	job.LockedBy = ""
+
+	switch {
+	case err == nil:
+		job.Status = utils.JobStatusCompleted
+		job.CompletedAt = &now
+		job.LastError = ""
+	case job.Attempts >= job.MaxAttempts:
+		job.Status = utils.JobStatusDead
+		job.LastError = err.Error()
+		log.Printf("Job %d (%s) moved to dead letter after %d attempts: %v", job.ID, job.Type, job.Attempts, err)
+	default:
+		job.Status = utils.JobStatusQueued
+		job.RunAt = now.Add(Backoff(job.Attempts))
+		job.LastError = err.Error()
+	}
+}
+
+func runHandler(ctx context.Context, handler Handler, db *gorm.DB, job models.Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+
+	return handler(ctx, db.WithContext(ctx), job)
+}
+
+// reclaimStaleJobs requeues running jobs whose worker died without
+// reporting back. Jobs that are out of attempts are dead-lettered instead,
+// so a job that keeps crashing its worker is not retried forever.
+func (p *WorkerPool) reclaimStaleJobs(ctx context.Context) {
+	ticker := time.NewTicker(p.lockTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stale := p.db.Model(&models.Job{}).
+				Where("queue = ? AND status = ? AND locked_at < ?", p.queue, utils.JobStatusRunning, time.Now().Add(-p.lockTimeout)).
+				Session(&gorm.Session{})
+
+			result := stale.Where("attempts >= max_attempts").
+				Updates(map[string]interface{}{"status": utils.JobStatusDead, "locked_at": nil, "locked_by": "", "last_error": "worker stopped responding"})
+			if result.Error != nil {
+				log.Printf("Could not dead-letter stale jobs: %v", result.Error)
+			} else if result.RowsAffected > 0 {
+				log.Printf("Moved %d stale jobs to dead letter", result.RowsAffected)
+			}
+
+			result = stale.Where("attempts < max_attempts").
+				Updates(map[string]interface{}{"status": utils.JobStatusQueued, "locked_at": nil, "locked_by": ""})
+			if result.Error != nil {
+				log.Printf("Could not reclaim stale jobs: %v", result.Error)
+			} else if result.RowsAffected > 0 {
+				log.Printf("Reclaimed %d stale jobs", result.RowsAffected)
+			}
+		}
+	}
+}
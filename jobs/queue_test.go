@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"api/models"
+	"api/utils"
+	"context"
+	"errors"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{4, 80 * time.Second},
+		{20, time.Hour},
+	}
+
+	for _, c := range cases {
+		for i := 0; i < 20; i++ {
+			got := Backoff(c.attempt)
+			if got < c.base || got > c.base+c.base/5 {
+				t.Fatalf("Backoff(%d) = %s, want within 20%% above %s", c.attempt, got, c.base)
+			}
+		}
+	}
+}
+
+func runningJob(attempts int, maxAttempts int) models.Job {
+	lockedAt := time.Now().Add(-time.Minute)
+	return models.Job{
+		Type:        "test",
+		Status:      utils.JobStatusRunning,
+		Attempts:    attempts,
+		MaxAttempts: maxAttempts,
+		LockedAt:    &lockedAt,
+		LockedBy:    "host-1/0",
+		LastError:   "earlier failure",
+	}
+}
+
+func TestFinishCompletesJob(t *testing.T) {
+	now := time.Now()
+	job := runningJob(2, 5)
+
+	finish(&job, nil, now)
+
+	if job.Status != utils.JobStatusCompleted {
+		t.Errorf("status = %s, want %s", job.Status, utils.JobStatusCompleted)
+	}
+	if job.CompletedAt == nil || !job.CompletedAt.Equal(now) {
+		t.Errorf("completed_at = %v, want %v", job.CompletedAt, now)
+	}
+	if job.LastError != "" || job.LockedAt != nil || job.LockedBy != "" {
+		t.Errorf("job kept error or lock: %+v", job)
+	}
+}
+
+func TestFinishRetriesWithBackoff(t *testing.T) {
+	now := time.Now()
+	job := runningJob(2, 5)
+
+	finish(&job, errors.New("boom"), now)
+
+	if job.Status != utils.JobStatusQueued {
+		t.Errorf("status = %s, want %s", job.Status, utils.JobStatusQueued)
+	}
+	if delay := job.RunAt.Sub(now); delay < 20*time.Second || delay > 24*time.Second {
+		t.Errorf("retry in %s, want the second attempt's backoff", delay)
+	}
+	if job.LastError != "boom" || job.LockedAt != nil || job.LockedBy != "" {
+		t.Errorf("job = %+v", job)
+	}
+}
+
+func TestFinishDeadLettersAfterLastAttempt(t *testing.T) {
+	job := runningJob(5, 5)
+
+	finish(&job, errors.New("still failing"), time.Now())
+
+	if job.Status != utils.JobStatusDead {
+		t.Errorf("status = %s, want %s", job.Status, utils.JobStatusDead)
+	}
+	if job.LastError != "still failing" || job.CompletedAt != nil {
+		t.Errorf("job = %+v", job)
+	}
+}
+
+func TestRunHandlerRecoversPanics(t *testing.T) {
+	handler := func(ctx context.Context, db *gorm.DB, job models.Job) error {
+		panic("nil map")
+	}
+
+	// The driver connects lazily, so no database is needed.
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	err = runHandler(context.Background(), handler, db, models.Job{})
+	if err == nil || err.Error() != "job panicked: nil map" {
+		t.Errorf("runHandler = %v, want the recovered panic", err)
+	}
+}
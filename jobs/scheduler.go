@@ -0,0 +1,92 @@
+package jobs
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"time"
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	db   *gorm.DB
+	jobs []Job
+}
+
+func NewScheduler(db *gorm.DB) *Scheduler {
+	return &Scheduler{db: db}
+}
+
+// Every registers a job to run on a fixed interval. Jobs with a
+// non-positive interval are disabled.
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	if interval <= 0 {
+		log.Printf("Job %s is disabled", name)
+		return
+	}
+
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+// loop runs the job once on start and then on every tick.
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.run(ctx, job); err != nil {
+			log.Printf("Job %s failed: %v", job.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run holds an advisory lock named after the job while it runs, so when
+// several replicas schedule the same job only one of them runs it at a time
+// and the others skip that tick.
+func (s *Scheduler) run(ctx context.Context, job Job) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", "scheduler:"+job.Name).Scan(&locked).Error; err != nil {
+			return err
+		}
+
+		if !locked {
+			return nil
+		}
+
+		return job.Run(ctx)
+	})
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+
+	return duration
+}
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"api/services"
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// RegisterShipmentJobs schedules pulling carrier tracking updates for
+// undelivered shipments every SHIPMENT_TRACKING_SYNC_INTERVAL (default 15m).
+func RegisterShipmentJobs(scheduler *Scheduler, db *gorm.DB) {
+	interval := durationFromEnv("SHIPMENT_TRACKING_SYNC_INTERVAL", 15*time.Minute)
+
+	scheduler.Every("shipment-tracking-sync", interval, func(ctx context.Context) error {
+		failed, err := services.SyncUndeliveredShipments(db.WithContext(ctx))
+		if failed > 0 {
+			log.Printf("Could not sync tracking for %d shipments", failed)
+		}
+		return err
+	})
+}
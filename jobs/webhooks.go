@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"api/events"
+	"api/models"
+	"api/services"
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+)
+
+const (
+	TypeWebhookDelivery = "webhook_delivery"
+
+	// WebhookMaxAttempts spreads retries over roughly an hour and a half with
+	// the default backoff.
+	WebhookMaxAttempts = 10
+)
+
+type WebhookDeliveryPayload struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+func init() {
+	RegisterHandler(TypeWebhookDelivery, func(ctx context.Context, db *gorm.DB, job models.Job) error {
+		var payload WebhookDeliveryPayload
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return err
+		}
+
+		return services.DeliverWebhook(ctx, db, payload.DeliveryID, job.Attempts >= job.MaxAttempts)
+	})
+}
+
+// WebhookSink fans published events out to the sellers' webhook endpoints,
+// queueing one delivery job per subscribed endpoint.
+type WebhookSink struct {
+	db *gorm.DB
+}
+
+func NewWebhookSink(db *gorm.DB) *WebhookSink {
+	return &WebhookSink{db: db}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event events.Event) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deliveries, err := services.CreateWebhookDeliveries(tx, event)
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			if _, err := Enqueue(tx, TypeWebhookDelivery, WebhookDeliveryPayload{DeliveryID: delivery.ID}, EnqueueOptions{MaxAttempts: WebhookMaxAttempts}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
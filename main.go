@@ -2,10 +2,17 @@ package main
 
 import (
 	"api/database"
+	"api/events"
+	"api/jobs"
 	"api/migrations"
+	"api/notifications"
 	"api/routes"
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 )
 
 func main() {
@@ -21,7 +28,37 @@ func main() {
 
 		log.Println("Migrations ran successfully")
 
+	case "worker":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		notifications.ConfigureFromEnv(database.GetDB())
+		jobs.RegisterPriceDropNotifications(database.GetDB())
+
+		scheduler := jobs.NewScheduler(database.GetDB())
+		jobs.RegisterShipmentJobs(scheduler, database.GetDB())
+		jobs.RegisterCustomerJobs(scheduler, database.GetDB())
+		jobs.RegisterMaintenanceJobs(scheduler, database.GetDB())
+		scheduler.Start(ctx)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			events.NewRelay(database.GetDB(), map[string]events.Sink{
+				"external":      events.SinkFromEnv(),
+				"webhooks":      jobs.NewWebhookSink(database.GetDB()),
+				"notifications": jobs.NewNotificationSink(database.GetDB()),
+			}).Run(ctx)
+		}()
+
+		jobs.NewWorkerPool(database.GetDB()).Run(ctx)
+		wg.Wait()
+		log.Println("Worker stopped")
+
 	case "customers", "sellers", "admins":
+		jobs.RegisterPriceDropNotifications(database.GetDB())
+
 		r := routes.SetupRouter(service)
 		port := os.Getenv("PORT")
 		
@@ -0,0 +1,124 @@
+package middlewares
+
+import (
+	"api/audit"
+	"api/database"
+	"api/models"
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const maxAuditedBodySize = 16 << 10
+
+// AuditMiddleware logs every routed mutating request; handlers add snapshots via audit.
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil && strings.HasPrefix(c.ContentType(), "application/json") {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxAuditedBodySize+1))
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		}
+
+		c.Next()
+
+		if c.FullPath() == "" {
+			return
+		}
+
+		entry := models.AuditLog{
+			RequestID:  c.GetString("request_id"),
+			ActorType:  c.GetString("user_type"),
+			Action:     auditAction(c),
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			StatusCode: c.Writer.Status(),
+			IP:         c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+			Request:    auditRequestBody(body),
+		}
+		if entry.ActorType == "" {
+			entry.ActorType = "anonymous"
+		}
+		if userID, exists := c.Get("user_id"); exists {
+			if id, ok := userID.(uint); ok {
+				entry.ActorID = &id
+			}
+		}
+
+		target, ok := audit.TargetOf(c)
+		if !ok {
+			target = routeTarget(c)
+		}
+		entry.TargetType = target.Type
+		entry.TargetID = target.ID
+
+		before, after := audit.Before(c), audit.After(c)
+		entry.Before = encodeAuditJSON(before)
+		entry.After = encodeAuditJSON(after)
+		entry.Diff = encodeAuditJSON(audit.Diff(before, after))
+
+		if err := database.GetDB().Create(&entry).Error; err != nil {
+			log.Printf("Could not write audit log for %s %s: %v", entry.Method, entry.Path, err)
+		}
+	}
+}
+
+// auditAction names the action after the handler, e.g. "DeleteSeller".
+func auditAction(c *gin.Context) string {
+	name := c.HandlerName()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// routeTarget is the resource before :id, or the route's last segment.
+func routeTarget(c *gin.Context) audit.Target {
+	segments := strings.Split(strings.Trim(c.FullPath(), "/"), "/")
+	for i, segment := range segments {
+		if segment == ":id" && i > 0 {
+			return audit.Target{Type: segments[i-1], ID: c.Param("id")}
+		}
+	}
+
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i] != "" && !strings.HasPrefix(segments[i], ":") {
+			return audit.Target{Type: segments[i]}
+		}
+	}
+
+	return audit.Target{}
+}
+
+func auditRequestBody(body []byte) string {
+	if len(body) == 0 {
+		return "null"
+	}
+	if len(body) > maxAuditedBodySize {
+		return `{"truncated": true}`
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return "null"
+	}
+
+	return encodeAuditJSON(audit.Redact(decoded))
+}
+
+func encodeAuditJSON(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "null"
+	}
+
+	return string(encoded)
+}
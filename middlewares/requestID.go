@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"api/utils"
+	"github.com/gin-gonic/gin"
+	"regexp"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware reuses a well-formed proxy request ID and echoes it back.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			token, err := utils.RandomToken()
+			if err == nil {
+				requestID = token
+			}
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"api/database"
+	"api/models"
+	"api/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ApprovedSellerMiddleware keeps unapproved sellers to their profile and application.
+func ApprovedSellerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var seller models.Seller
+		if err := database.GetDB().Select("id", "status").First(&seller, c.GetUint("user_id")).Error; err != nil {
+			utils.ErrorJSON(c, http.StatusForbidden, gin.H{
+				"error": "Access forbidden: seller not found",
+			})
+			c.Abort()
+			return
+		}
+
+		if seller.Status != utils.SellerStatusApproved {
+			utils.ErrorJSON(c, http.StatusForbidden, gin.H{
+				"error":  "Access forbidden: seller account is " + seller.Status,
+				"status": seller.Status,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -2,19 +2,181 @@ package migrations
 
 import (
 	"api/models"
+	"api/services"
+	"api/utils"
 	"gorm.io/gorm"
 )
 
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
 		&models.Customer{},
+		&models.Address{},
 		&models.Seller{},
 		&models.Admin{},
 		&models.Product{},
 		&models.Cart{},
 		&models.Order{},
+		&models.SubOrder{},
 		&models.Payment{},
+		&models.PaymentTransaction{},
 		&models.ShippingInfo{},
 		&models.CartItem{},
-	)
+		&models.Wishlist{},
+		&models.WishlistItem{},
+		&models.Review{},
+		&models.SellerRating{},
+		&models.SellerApplication{},
+		&models.SellerDocument{},
+		&models.ProductImport{},
+		&models.ProductImportError{},
+		&models.Job{},
+		&models.OutboxEvent{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.AuditLog{},
+		&models.TaxRate{},
+		&models.Promotion{},
+		&models.Coupon{},
+		&models.PromotionRedemption{},
+		&models.OrderDiscount{},
+		&models.GiftCard{},
+		&models.StoreCreditEntry{},
+		&models.ShippingZone{},
+		&models.ShippingRate{},
+		&models.Shipment{},
+		&models.ShipmentItem{},
+		&models.TrackingEvent{},
+		&models.CommissionRule{},
+		&models.PayoutBatch{},
+		&models.Payout{},
+		&models.SellerLedgerEntry{},
+	); err != nil {
+		return err
+	}
+
+	if err := backfillStoreSlugs(db); err != nil {
+		return err
+	}
+
+	if err := backfillSellerStatuses(db); err != nil {
+		return err
+	}
+
+	if err := backfillProductStatuses(db); err != nil {
+		return err
+	}
+
+	if err := backfillCustomerAddresses(db); err != nil {
+		return err
+	}
+
+	if err := protectOrderedProducts(db); err != nil {
+		return err
+	}
+
+	return protectAuditLogs(db)
+}
+
+// backfillSellerStatuses approves sellers created before the onboarding
+// workflow existed so their stores stay live.
+func backfillSellerStatuses(db *gorm.DB) error {
+	return db.Model(&models.Seller{}).
+		Where("status IS NULL OR status = ''").
+		UpdateColumn("status", utils.SellerStatusApproved).Error
+}
+
+func backfillStoreSlugs(db *gorm.DB) error {
+	var sellers []models.Seller
+	if err := db.Where("store_slug = ''").Find(&sellers).Error; err != nil {
+		return err
+	}
+
+	for _, seller := range sellers {
+		slug, err := services.UniqueStoreSlug(db, seller.StoreName, seller.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := db.Model(&seller).UpdateColumn("store_slug", slug).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillProductStatuses publishes products created before moderation
+// existed so the catalog does not empty out on upgrade.
+func backfillProductStatuses(db *gorm.DB) error {
+	return db.Model(&models.Product{}).
+		Where("status IS NULL OR status = ''").
+		UpdateColumn("status", utils.ProductStatusPublished).Error
+}
+
+// protectAuditLogs makes the audit log append-only at the database level,
+// so neither application bugs nor ad-hoc queries can rewrite history.
+func protectAuditLogs(db *gorm.DB) error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION reject_audit_log_changes() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`,
+		`CREATE TRIGGER audit_logs_append_only
+	BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+	FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_log_changes()`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillCustomerAddresses moves the free-text address customers had
+// before the address book into a default address.
+func backfillCustomerAddresses(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("customers", "address") {
+		return nil
+	}
+
+	return db.Exec(`INSERT INTO addresses (created_at, updated_at, customer_id, label, full_name, line1, phone, is_default_shipping, is_default_billing)
+SELECT NOW(), NOW(), customers.id, 'Primary', customers.name, customers.address, customers.phone, TRUE, TRUE
+FROM customers
+WHERE customers.address <> ''
+AND NOT EXISTS (SELECT 1 FROM addresses WHERE addresses.customer_id = customers.id)`).Error
+}
+
+// protectOrderedProducts refuses hard deletes of products that appear on
+// an order, since cart_items would cascade and take order history with it.
+// Soft deletes are updates and stay allowed.
+func protectOrderedProducts(db *gorm.DB) error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION reject_ordered_product_deletes() RETURNS trigger AS $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM cart_items JOIN orders ON orders.cart_id = cart_items.cart_id WHERE cart_items.product_id = OLD.id) THEN
+		RAISE EXCEPTION 'product % is referenced by orders', OLD.id;
+	END IF;
+	RETURN OLD;
+END;
+$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS products_keep_ordered ON products`,
+		`CREATE TRIGGER products_keep_ordered
+	BEFORE DELETE ON products
+	FOR EACH ROW EXECUTE FUNCTION reject_ordered_product_deletes()`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
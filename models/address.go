@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"gorm.io/gorm"
+)
+
+type AddressFields struct {
+	FullName   string `json:"full_name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone"`
+}
+
+type Address struct {
+	gorm.Model
+	CustomerID        uint          `json:"customer_id" gorm:"index"`
+	Customer          *Customer     `gorm:"foreignKey:customer_id;constraint:OnDelete:CASCADE;"`
+	Label             string        `json:"label"`
+	Fields            AddressFields `json:"address" gorm:"embedded"`
+	IsDefaultShipping bool          `json:"is_default_shipping"`
+	IsDefaultBilling  bool          `json:"is_default_billing"`
+}
+
+// AddressInput is an address in a request body. Older clients send the
+// address as a single line of text, which is kept as Line1 and marked
+// Legacy.
+type AddressInput struct {
+	AddressFields
+	Legacy bool `json:"-"`
+}
+
+func (a *AddressInput) UnmarshalJSON(data []byte) error {
+	var line string
+	if err := json.Unmarshal(data, &line); err == nil {
+		a.AddressFields = AddressFields{Line1: line}
+		a.Legacy = true
+		return nil
+	}
+
+	a.Legacy = false
+	return json.Unmarshal(data, &a.AddressFields)
+}
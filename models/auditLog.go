@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditLog rows are append-only: the migration installs a trigger that
+// rejects updates and deletes.
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+	RequestID  string    `json:"request_id" gorm:"index"`
+	ActorType  string    `json:"actor_type" gorm:"index:idx_audit_logs_actor,priority:1"`
+	ActorID    *uint     `json:"actor_id" gorm:"index:idx_audit_logs_actor,priority:2"`
+	Action     string    `json:"action" gorm:"index"`
+	TargetType string    `json:"target_type" gorm:"index:idx_audit_logs_target,priority:1"`
+	TargetID   string    `json:"target_id" gorm:"index:idx_audit_logs_target,priority:2"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Request    string    `json:"request" gorm:"type:jsonb"`
+	Before     string    `json:"before" gorm:"type:jsonb"`
+	After      string    `json:"after" gorm:"type:jsonb"`
+	Diff       string    `json:"diff" gorm:"type:jsonb"`
+}
@@ -2,15 +2,19 @@ package models
 
 import (
 	"gorm.io/gorm"
+	"time"
 )
 
 type Cart struct {
 	gorm.Model
-	CustomerID uint       `json:"customer_id"`
+	CustomerID *uint      `json:"customer_id"`
 	Customer   Customer   `gorm:"foreignKey:customer_id"`
+	GuestToken string     `json:"-" gorm:"index"`
 	Products   []Product  `gorm:"many2many:cart_items;"`
 	CartItems  []CartItem `gorm:"foreignKey:cart_id"`
 	TotalPrice float64    `json:"total_price"`
+	CouponCode string     `json:"coupon_code"`
 	IsActive   bool       `json:"is_active"`
+	RemindedAt *time.Time `json:"-"`
 	Order      *Order     `gorm:"constraint:OnDelete:CASCADE;"`
 }
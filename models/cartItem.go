@@ -6,10 +6,17 @@ import (
 
 type CartItem struct {
 	gorm.Model
-	CartID    uint     `json:"cart_id"`
-	Cart      *Cart    `gorm:"foreignKey:cart_id;constraint:OnDelete:CASCADE;"`
-	ProductID uint     `json:"product_id"`
-	Product   *Product `gorm:"foreignKey:product_id;constraint:OnDelete:CASCADE;"`
-	Quantity  int      `json:"quantity"`
-	Status    string   `json:"status" gorm:"default:'pending'"`
+	CartID         uint      `json:"cart_id"`
+	Cart           *Cart     `gorm:"foreignKey:cart_id;constraint:OnDelete:CASCADE;"`
+	ProductID      uint      `json:"product_id"`
+	Product        *Product  `gorm:"foreignKey:product_id;constraint:OnDelete:CASCADE;"`
+	SubOrderID     *uint     `json:"sub_order_id" gorm:"index"`
+	SubOrder       *SubOrder `gorm:"foreignKey:sub_order_id;constraint:OnDelete:SET NULL;"`
+	Quantity       int       `json:"quantity"`
+	UnitPrice      float64   `json:"unit_price"`
+	DiscountAmount float64   `json:"discount_amount"`
+	TaxRate        float64   `json:"tax_rate"`
+	TaxAmount      float64   `json:"tax_amount"`
+	TaxInclusive   bool      `json:"tax_inclusive"`
+	Status         string    `json:"status" gorm:"default:'pending'"`
 }
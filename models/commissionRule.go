@@ -0,0 +1,13 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type CommissionRule struct {
+	gorm.Model
+	SellerID *uint   `json:"seller_id" gorm:"index"`
+	Seller   *Seller `gorm:"foreignKey:seller_id;constraint:OnDelete:CASCADE;"`
+	Category string  `json:"category"`
+	Rate     float64 `json:"rate"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type Coupon struct {
+	gorm.Model
+	PromotionID      uint       `json:"promotion_id" gorm:"index"`
+	Promotion        *Promotion `gorm:"foreignKey:promotion_id;constraint:OnDelete:CASCADE;"`
+	Code             string     `json:"code" gorm:"uniqueIndex"`
+	UsageLimit       int        `json:"usage_limit"`
+	PerCustomerLimit int        `json:"per_customer_limit"`
+	UsageCount       int        `json:"usage_count"`
+	Active           bool       `json:"active"`
+}
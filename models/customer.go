@@ -2,6 +2,7 @@ package models
 
 type Customer struct {
 	User
-	Address string `json:"address"`
-	Carts   []Cart `gorm:"foreignKey:customer_id"`
+	StoreCreditBalance float64   `json:"store_credit_balance"`
+	Carts              []Cart    `gorm:"foreignKey:customer_id"`
+	Addresses          []Address `gorm:"foreignKey:customer_id"`
 }
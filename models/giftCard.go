@@ -0,0 +1,18 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type GiftCard struct {
+	gorm.Model
+	Code           string     `json:"code" gorm:"uniqueIndex"`
+	InitialBalance float64    `json:"initial_balance"`
+	Balance        float64    `json:"balance"`
+	Note           string     `json:"note"`
+	IssuedByID     uint       `json:"issued_by_id"`
+	RedeemedByID   *uint      `json:"redeemed_by_id"`
+	RedeemedAt     *time.Time `json:"redeemed_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Job struct {
+	gorm.Model
+	Queue       string     `json:"queue" gorm:"index:idx_jobs_claim,priority:1"`
+	Type        string     `json:"type" gorm:"index"`
+	Payload     string     `json:"payload" gorm:"type:jsonb;default:'{}'"`
+	Status      string     `json:"status" gorm:"index:idx_jobs_claim,priority:2"`
+	RunAt       time.Time  `json:"run_at" gorm:"index:idx_jobs_claim,priority:3"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	LockedAt    *time.Time `json:"locked_at"`
+	LockedBy    string     `json:"locked_by"`
+	LastError   string     `json:"last_error"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Notification struct {
+	gorm.Model
+	UserType string     `json:"user_type" gorm:"index:idx_notifications_user,priority:1"`
+	UserID   uint       `json:"user_id" gorm:"index:idx_notifications_user,priority:2"`
+	Kind     string     `json:"kind"`
+	Title    string     `json:"title"`
+	Body     string     `json:"body"`
+	Data     string     `json:"data" gorm:"type:jsonb;default:'{}'"`
+	ReadAt   *time.Time `json:"read_at"`
+}
+
+type NotificationPreference struct {
+	gorm.Model
+	UserType     string `json:"user_type" gorm:"uniqueIndex:idx_notification_preferences_user"`
+	UserID       uint   `json:"user_id" gorm:"uniqueIndex:idx_notification_preferences_user"`
+	Locale       string `json:"locale"`
+	EmailEnabled bool   `json:"email_enabled"`
+	SMSEnabled   bool   `json:"sms_enabled"`
+	InAppEnabled bool   `json:"in_app_enabled"`
+	MutedKinds   string `json:"muted_kinds"`
+}
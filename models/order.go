@@ -7,11 +7,18 @@ import (
 
 type Order struct {
 	gorm.Model
-	CartID       uint          `json:"cart_id"`
-	Cart         *Cart         `gorm:"foreignKey:cart_id"`
-	TotalAmount  float64       `json:"total_amount"`
-	OrderedDate  time.Time     `json:"ordered_date"`
-	Status       string        `json:"status"`
-	Payment      *Payment      `gorm:"constraint:OnDelete:CASCADE;"`
-	ShippingInfo *ShippingInfo `gorm:"constraint:OnDelete:CASCADE;"`
+	CartID         uint            `json:"cart_id"`
+	Cart           *Cart           `gorm:"foreignKey:cart_id"`
+	Subtotal       float64         `json:"subtotal"`
+	ShippingCost   float64         `json:"shipping_cost"`
+	DiscountAmount float64         `json:"discount_amount"`
+	TaxAmount      float64         `json:"tax_amount"`
+	TotalAmount    float64         `json:"total_amount"`
+	OrderedDate    time.Time       `json:"ordered_date"`
+	Status         string          `json:"status"`
+	BillingAddress AddressFields   `json:"billing_address" gorm:"embedded;embeddedPrefix:billing_"`
+	Payment        *Payment        `gorm:"constraint:OnDelete:CASCADE;"`
+	ShippingInfo   *ShippingInfo   `gorm:"constraint:OnDelete:CASCADE;"`
+	SubOrders      []SubOrder      `gorm:"foreignKey:order_id"`
+	Discounts      []OrderDiscount `gorm:"foreignKey:order_id"`
 }
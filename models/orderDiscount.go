@@ -0,0 +1,17 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type OrderDiscount struct {
+	gorm.Model
+	OrderID     uint    `json:"order_id" gorm:"index"`
+	Order       *Order  `gorm:"foreignKey:order_id;constraint:OnDelete:CASCADE;"`
+	PromotionID uint    `json:"promotion_id"`
+	SellerID    *uint   `json:"seller_id"`
+	CouponCode  string  `json:"coupon_code"`
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
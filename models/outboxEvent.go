@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+type OutboxEvent struct {
+	ID             uint       `json:"id" gorm:"primarykey"`
+	EventID        string     `json:"event_id" gorm:"uniqueIndex"`
+	Type           string     `json:"type" gorm:"index"`
+	Version        int        `json:"version"`
+	AggregateType  string     `json:"aggregate_type"`
+	AggregateID    uint       `json:"aggregate_id"`
+	SellerIDs      string     `json:"seller_ids"`
+	Payload        string     `json:"payload" gorm:"type:jsonb"`
+	OccurredAt     time.Time  `json:"occurred_at"`
+	PublishedAt    *time.Time `json:"published_at" gorm:"index"`
+	PublishedSinks string     `json:"published_sinks"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastError      string     `json:"last_error"`
+}
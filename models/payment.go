@@ -6,8 +6,20 @@ import (
 
 type Payment struct {
 	gorm.Model
-	OrderID     uint    `json:"order_id"`
-	Order       *Order  `gorm:"foreignKey:order_id;constraint:OnDelete:CASCADE;"`
-	TotalAmount float64 `json:"total_amount"`
-	Paid        bool    `json:"paid"`
+	OrderID      uint                 `json:"order_id"`
+	Order        *Order               `gorm:"foreignKey:order_id;constraint:OnDelete:CASCADE;"`
+	TotalAmount  float64              `json:"total_amount"`
+	Paid         bool                 `json:"paid"`
+	Status       string               `json:"status" gorm:"default:'unpaid'"`
+	Transactions []PaymentTransaction `gorm:"foreignKey:payment_id"`
+	Balance      *PaymentBalance      `json:"balance" gorm:"-"`
+}
+
+type PaymentBalance struct {
+	Authorized  float64 `json:"authorized"`
+	Captured    float64 `json:"captured"`
+	Refunded    float64 `json:"refunded"`
+	ChargedBack float64 `json:"charged_back"`
+	Net         float64 `json:"net"`
+	Outstanding float64 `json:"outstanding"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type PaymentTransaction struct {
+	gorm.Model
+	PaymentID uint     `json:"payment_id" gorm:"index"`
+	Payment   *Payment `gorm:"foreignKey:payment_id;constraint:OnDelete:CASCADE;"`
+	Type      string   `json:"type"`
+	Method    string   `json:"method"`
+	Amount    float64  `json:"amount"`
+	Reference string   `json:"reference"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Payout struct {
+	gorm.Model
+	PayoutBatchID uint         `json:"payout_batch_id" gorm:"index"`
+	PayoutBatch   *PayoutBatch `gorm:"foreignKey:payout_batch_id;constraint:OnDelete:CASCADE;"`
+	SellerID      uint         `json:"seller_id" gorm:"index"`
+	Seller        *Seller      `gorm:"foreignKey:seller_id;constraint:OnDelete:CASCADE;"`
+	Amount        float64      `json:"amount"`
+	Status        string       `json:"status"`
+	Reference     string       `json:"reference"`
+	PaidAt        *time.Time   `json:"paid_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type PayoutBatch struct {
+	gorm.Model
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Status      string    `json:"status"`
+	TotalAmount float64   `json:"total_amount"`
+	Payouts     []Payout  `gorm:"foreignKey:payout_batch_id"`
+}
@@ -2,14 +2,29 @@ package models
 
 import (
 	"gorm.io/gorm"
+	"time"
 )
 
 type Product struct {
 	gorm.Model
-	Name        string  `json:"name"`
-	SKU         string  `json:"sku"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	SellerId    uint    `json:"seller_id"`
-	Seller      *Seller `gorm:"foreignKey:seller_id"`
+	Name             string     `json:"name"`
+	SKU              string     `json:"sku"`
+	Description      string     `json:"description"`
+	Category         string     `json:"category" gorm:"index"`
+	Price            float64    `json:"price"`
+	TaxClass         string     `json:"tax_class" gorm:"default:'standard'"`
+	WeightKg         float64    `json:"weight_kg"`
+	LengthCm         float64    `json:"length_cm"`
+	WidthCm          float64    `json:"width_cm"`
+	HeightCm         float64    `json:"height_cm"`
+	AverageRating    float64    `json:"average_rating"`
+	ReviewCount      int        `json:"review_count"`
+	Status           string     `json:"status" gorm:"index"`
+	ModerationFlags  string     `json:"moderation_flags"`
+	ModerationReason string     `json:"moderation_reason"`
+	SubmittedAt      *time.Time `json:"submitted_at"`
+	ReviewedAt       *time.Time `json:"reviewed_at"`
+	ReviewedBy       *uint      `json:"reviewed_by"`
+	SellerId         uint       `json:"seller_id"`
+	Seller           *Seller    `gorm:"foreignKey:seller_id"`
 }
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type ProductImport struct {
+	gorm.Model
+	SellerID      uint                 `json:"seller_id" gorm:"index"`
+	Seller        *Seller              `json:"-" gorm:"foreignKey:seller_id;constraint:OnDelete:CASCADE;"`
+	Format        string               `json:"format"`
+	FileName      string               `json:"file_name"`
+	StoragePath   string               `json:"-"`
+	Status        string               `json:"status"`
+	TotalRows     int                  `json:"total_rows"`
+	ProcessedRows int                  `json:"processed_rows"`
+	CreatedCount  int                  `json:"created_count"`
+	UpdatedCount  int                  `json:"updated_count"`
+	FailedCount   int                  `json:"failed_count"`
+	Message       string               `json:"message,omitempty"`
+	StartedAt     *time.Time           `json:"started_at"`
+	FinishedAt    *time.Time           `json:"finished_at"`
+	Errors        []ProductImportError `json:"errors,omitempty" gorm:"foreignKey:product_import_id"`
+}
+
+type ProductImportError struct {
+	gorm.Model
+	ProductImportID uint   `json:"product_import_id" gorm:"index"`
+	Row             int    `json:"row"`
+	SKU             string `json:"sku"`
+	Message         string `json:"message"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Promotion struct {
+	gorm.Model
+	Name             string     `json:"name"`
+	Description      string     `json:"description"`
+	SellerID         *uint      `json:"seller_id" gorm:"index"`
+	Seller           *Seller    `gorm:"foreignKey:seller_id;constraint:OnDelete:CASCADE;"`
+	Type             string     `json:"type"`
+	Value            float64    `json:"value"`
+	ProductID        *uint      `json:"product_id"`
+	Category         string     `json:"category"`
+	BuyQuantity      int        `json:"buy_quantity"`
+	GetQuantity      int        `json:"get_quantity"`
+	MinSubtotal      float64    `json:"min_subtotal"`
+	StartsAt         *time.Time `json:"starts_at"`
+	EndsAt           *time.Time `json:"ends_at"`
+	UsageLimit       int        `json:"usage_limit"`
+	PerCustomerLimit int        `json:"per_customer_limit"`
+	UsageCount       int        `json:"usage_count"`
+	RequiresCoupon   bool       `json:"requires_coupon"`
+	Active           bool       `json:"active"`
+	Coupons          []Coupon   `gorm:"foreignKey:promotion_id"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type PromotionRedemption struct {
+	gorm.Model
+	PromotionID uint       `json:"promotion_id" gorm:"index"`
+	Promotion   *Promotion `gorm:"foreignKey:promotion_id;constraint:OnDelete:CASCADE;"`
+	CouponID    *uint      `json:"coupon_id"`
+	CustomerID  uint       `json:"customer_id" gorm:"index"`
+	OrderID     uint       `json:"order_id" gorm:"index"`
+	Order       *Order     `gorm:"foreignKey:order_id;constraint:OnDelete:CASCADE;"`
+	Amount      float64    `json:"amount"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Review struct {
+	gorm.Model
+	ProductID       uint       `json:"product_id" gorm:"uniqueIndex:idx_review_product_customer"`
+	Product         *Product   `gorm:"foreignKey:product_id;constraint:OnDelete:CASCADE;"`
+	CustomerID      uint       `json:"customer_id" gorm:"uniqueIndex:idx_review_product_customer"`
+	Customer        *Customer  `gorm:"foreignKey:customer_id;constraint:OnDelete:CASCADE;"`
+	OrderID         uint       `json:"order_id"`
+	Rating          int        `json:"rating"`
+	Title           string     `json:"title"`
+	Body            string     `json:"body"`
+	IsHidden        bool       `json:"is_hidden" gorm:"index"`
+	HiddenReason    string     `json:"hidden_reason,omitempty"`
+	SellerReply     string     `json:"seller_reply,omitempty"`
+	SellerRepliedAt *time.Time `json:"seller_replied_at,omitempty"`
+}
@@ -2,6 +2,20 @@ package models
 
 type Seller struct {
 	User
-	StoreName string    `json:"store_name"`
-	Products  []Product `gorm:"foreignKey:seller_id"`
+	StoreName        string             `json:"store_name"`
+	Status           string             `json:"status" gorm:"index"`
+	StatusReason     string             `json:"status_reason"`
+	StoreSlug        string             `json:"store_slug" gorm:"uniqueIndex:idx_sellers_store_slug,where:store_slug <> ''"`
+	StoreDescription string             `json:"store_description"`
+	LogoURL          string             `json:"logo_url"`
+	ReturnPolicy     string             `json:"return_policy"`
+	ShippingPolicy   string             `json:"shipping_policy"`
+	ContactEmail     string             `json:"contact_email"`
+	ContactPhone     string             `json:"contact_phone"`
+	StoreStatus      string             `json:"store_status" gorm:"default:'open'"`
+	VacationMessage  string             `json:"vacation_message"`
+	AverageRating    float64            `json:"average_rating"`
+	RatingCount      int                `json:"rating_count"`
+	Products         []Product          `gorm:"foreignKey:seller_id"`
+	Application      *SellerApplication `gorm:"foreignKey:seller_id"`
 }
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type SellerApplication struct {
+	gorm.Model
+	SellerID           uint             `json:"seller_id" gorm:"uniqueIndex"`
+	Seller             *Seller          `json:"seller,omitempty" gorm:"foreignKey:seller_id;constraint:OnDelete:CASCADE;"`
+	BusinessName       string           `json:"business_name"`
+	BusinessType       string           `json:"business_type"`
+	RegistrationNumber string           `json:"registration_number"`
+	TaxID              string           `json:"tax_id"`
+	BusinessAddress    AddressFields    `json:"business_address" gorm:"embedded;embeddedPrefix:business_"`
+	Status             string           `json:"status" gorm:"index"`
+	ReviewNotes        string           `json:"review_notes"`
+	ReviewedBy         *uint            `json:"reviewed_by"`
+	ReviewedAt         *time.Time       `json:"reviewed_at"`
+	SubmittedAt        *time.Time       `json:"submitted_at"`
+	Documents          []SellerDocument `json:"documents" gorm:"foreignKey:seller_application_id"`
+}
+
+type SellerDocument struct {
+	gorm.Model
+	SellerApplicationID uint   `json:"seller_application_id" gorm:"index"`
+	DocumentType        string `json:"document_type"`
+	FileName            string `json:"file_name"`
+	ContentType         string `json:"content_type"`
+	Size                int64  `json:"size"`
+	StoragePath         string `json:"-"`
+}
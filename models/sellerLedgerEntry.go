@@ -0,0 +1,19 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type SellerLedgerEntry struct {
+	gorm.Model
+	SellerID    uint      `json:"seller_id" gorm:"index"`
+	Seller      *Seller   `gorm:"foreignKey:seller_id;constraint:OnDelete:CASCADE;"`
+	OrderID     *uint     `json:"order_id" gorm:"index"`
+	Order       *Order    `gorm:"foreignKey:order_id;constraint:OnDelete:SET NULL;"`
+	SubOrderID  *uint     `json:"sub_order_id" gorm:"index"`
+	SubOrder    *SubOrder `gorm:"foreignKey:sub_order_id;constraint:OnDelete:SET NULL;"`
+	PayoutID    *uint     `json:"payout_id" gorm:"index"`
+	Type        string    `json:"type"`
+	Amount      float64   `json:"amount"`
+	Description string    `json:"description"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type SellerRating struct {
+	gorm.Model
+	SubOrderID uint      `json:"sub_order_id" gorm:"uniqueIndex"`
+	SubOrder   *SubOrder `gorm:"foreignKey:sub_order_id;constraint:OnDelete:CASCADE;"`
+	SellerID   uint      `json:"seller_id" gorm:"index"`
+	CustomerID uint      `json:"customer_id" gorm:"index"`
+	Rating     int       `json:"rating"`
+	Comment    string    `json:"comment"`
+}
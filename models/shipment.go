@@ -0,0 +1,21 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Shipment struct {
+	gorm.Model
+	SubOrderID     uint            `json:"sub_order_id" gorm:"index"`
+	SubOrder       *SubOrder       `gorm:"foreignKey:sub_order_id;constraint:OnDelete:CASCADE;"`
+	OrderID        uint            `json:"order_id" gorm:"index"`
+	SellerID       uint            `json:"seller_id" gorm:"index"`
+	Carrier        string          `json:"carrier"`
+	TrackingNumber string          `json:"tracking_number" gorm:"index"`
+	Status         string          `json:"status"`
+	ShippedAt      *time.Time      `json:"shipped_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	Items          []ShipmentItem  `gorm:"foreignKey:shipment_id"`
+	Events         []TrackingEvent `gorm:"foreignKey:shipment_id"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type ShipmentItem struct {
+	gorm.Model
+	ShipmentID uint      `json:"shipment_id" gorm:"index"`
+	Shipment   *Shipment `gorm:"foreignKey:shipment_id;constraint:OnDelete:CASCADE;"`
+	CartItemID uint      `json:"cart_item_id" gorm:"index"`
+	CartItem   *CartItem `gorm:"foreignKey:cart_item_id;constraint:OnDelete:CASCADE;"`
+	Quantity   int       `json:"quantity"`
+}
@@ -6,7 +6,10 @@ import (
 
 type ShippingInfo struct {
 	gorm.Model
-	OrderID uint   `json:"order_id"`
-	Order   *Order `gorm:"foreignKey:order_id;constraint:OnDelete:CASCADE;"`
-	Address string `json:"address"`
+	OrderID         uint          `json:"order_id"`
+	Order           *Order        `gorm:"foreignKey:order_id;constraint:OnDelete:CASCADE;"`
+	SubOrderID      *uint         `json:"sub_order_id" gorm:"index"`
+	SubOrder        *SubOrder     `gorm:"foreignKey:sub_order_id;constraint:OnDelete:CASCADE;"`
+	Address         string        `json:"address"`
+	ShippingAddress AddressFields `json:"shipping_address" gorm:"embedded;embeddedPrefix:address_"`
 }
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type ShippingRate struct {
+	gorm.Model
+	ShippingZoneID uint          `json:"shipping_zone_id" gorm:"index"`
+	ShippingZone   *ShippingZone `gorm:"foreignKey:shipping_zone_id;constraint:OnDelete:CASCADE;"`
+	Name           string        `json:"name"`
+	Type           string        `json:"type"`
+	Amount         float64       `json:"amount"`
+	PerKg          float64       `json:"per_kg"`
+	FreeThreshold  float64       `json:"free_threshold"`
+	MinWeight      float64       `json:"min_weight"`
+	MaxWeight      float64       `json:"max_weight"`
+}
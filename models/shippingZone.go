@@ -0,0 +1,14 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type ShippingZone struct {
+	gorm.Model
+	SellerID  uint           `json:"seller_id" gorm:"index"`
+	Seller    *Seller        `gorm:"foreignKey:seller_id;constraint:OnDelete:CASCADE;"`
+	Name      string         `json:"name"`
+	Countries string         `json:"countries"`
+	Rates     []ShippingRate `gorm:"foreignKey:shipping_zone_id"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type StoreCreditEntry struct {
+	gorm.Model
+	CustomerID   uint      `json:"customer_id" gorm:"index"`
+	Customer     *Customer `gorm:"foreignKey:customer_id;constraint:OnDelete:CASCADE;"`
+	Type         string    `json:"type"`
+	Amount       float64   `json:"amount"`
+	BalanceAfter float64   `json:"balance_after"`
+	GiftCardID   *uint     `json:"gift_card_id"`
+	OrderID      *uint     `json:"order_id"`
+	Description  string    `json:"description"`
+}
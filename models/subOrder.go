@@ -0,0 +1,25 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type SubOrder struct {
+	gorm.Model
+	OrderID        uint          `json:"order_id" gorm:"index"`
+	Order          *Order        `gorm:"foreignKey:order_id;constraint:OnDelete:CASCADE;"`
+	SellerID       uint          `json:"seller_id" gorm:"index"`
+	Seller         *Seller       `gorm:"foreignKey:seller_id"`
+	Status         string        `json:"status"`
+	Subtotal       float64       `json:"subtotal"`
+	DiscountAmount float64       `json:"discount_amount"`
+	TaxAmount      float64       `json:"tax_amount"`
+	ShippingRateID *uint         `json:"shipping_rate_id"`
+	ShippingMethod string        `json:"shipping_method"`
+	ShippingCost   float64       `json:"shipping_cost"`
+	Carrier        string        `json:"carrier"`
+	TrackingNumber string        `json:"tracking_number"`
+	CartItems      []CartItem    `gorm:"foreignKey:sub_order_id"`
+	ShippingInfo   *ShippingInfo `gorm:"foreignKey:sub_order_id;constraint:OnDelete:CASCADE;"`
+	Shipments      []Shipment    `gorm:"foreignKey:sub_order_id"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type TaxRate struct {
+	gorm.Model
+	Name      string  `json:"name"`
+	Country   string  `json:"country" gorm:"index"`
+	Region    string  `json:"region"`
+	TaxClass  string  `json:"tax_class"`
+	Rate      float64 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+}
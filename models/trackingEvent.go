@@ -0,0 +1,16 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type TrackingEvent struct {
+	gorm.Model
+	ShipmentID  uint      `json:"shipment_id" gorm:"index"`
+	Shipment    *Shipment `gorm:"foreignKey:shipment_id;constraint:OnDelete:CASCADE;"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
@@ -6,8 +6,8 @@ import (
 
 type User struct {
 	gorm.Model
-	Email    string `json:"email" gorm:"unique"`
-	Phone    string `json:"phone" gorm:"unique"`
+	Email    string `json:"email" gorm:"uniqueIndex:,where:deleted_at IS NULL"`
+	Phone    string `json:"phone" gorm:"uniqueIndex:,where:deleted_at IS NULL"`
 	Password string `json:"password"`
 	Name     string `json:"name"`
 }
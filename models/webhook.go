@@ -0,0 +1,38 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type WebhookEndpoint struct {
+	gorm.Model
+	SellerID            uint       `json:"seller_id" gorm:"index"`
+	Seller              *Seller    `json:"-" gorm:"foreignKey:seller_id;constraint:OnDelete:CASCADE;"`
+	URL                 string     `json:"url"`
+	Description         string     `json:"description"`
+	Secret              string     `json:"-"`
+	EventTypes          string     `json:"event_types"`
+	IsActive            bool       `json:"is_active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+}
+
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookEndpointID uint             `json:"webhook_endpoint_id" gorm:"uniqueIndex:idx_webhook_delivery_event"`
+	WebhookEndpoint   *WebhookEndpoint `json:"-" gorm:"foreignKey:webhook_endpoint_id;constraint:OnDelete:CASCADE;"`
+	EventID           string           `json:"event_id" gorm:"uniqueIndex:idx_webhook_delivery_event"`
+	EventType         string           `json:"event_type"`
+	Payload           string           `json:"payload" gorm:"type:jsonb"`
+	IsTest            bool             `json:"is_test"`
+	Status            string           `json:"status" gorm:"index"`
+	Attempts          int              `json:"attempts"`
+	ResponseStatus    int              `json:"response_status"`
+	ResponseBody      string           `json:"-"`
+	LastError         string           `json:"last_error,omitempty"`
+	DurationMs        int64            `json:"duration_ms"`
+	LastAttemptAt     *time.Time       `json:"last_attempt_at"`
+	DeliveredAt       *time.Time       `json:"delivered_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type Wishlist struct {
+	gorm.Model
+	CustomerID     uint           `json:"customer_id" gorm:"index"`
+	Customer       *Customer      `gorm:"foreignKey:customer_id;constraint:OnDelete:CASCADE;"`
+	Name           string         `json:"name"`
+	IsSaveForLater bool           `json:"is_save_for_later"`
+	IsPublic       bool           `json:"is_public"`
+	ShareToken     string         `json:"share_token,omitempty" gorm:"uniqueIndex"`
+	Items          []WishlistItem `json:"items" gorm:"foreignKey:wishlist_id"`
+}
+
+type WishlistItem struct {
+	gorm.Model
+	WishlistID uint      `json:"wishlist_id" gorm:"uniqueIndex:idx_wishlist_product"`
+	ProductID  uint      `json:"product_id" gorm:"uniqueIndex:idx_wishlist_product"`
+	Product    Product   `json:"product" gorm:"foreignKey:product_id"`
+	Quantity   int       `json:"quantity" gorm:"default:1"`
+	AddedPrice float64   `json:"added_price"`
+	Wishlist   *Wishlist `json:"-" gorm:"foreignKey:wishlist_id;constraint:OnDelete:CASCADE;"`
+}
@@ -0,0 +1,184 @@
+package notifications
+
+import (
+	"api/models"
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigureFromEnv registers the default adapters: SMTP email when
+// SMTP_HOST is set (logging otherwise), SMS through a logging provider and
+// the in-app inbox. Register a Channel afterwards to replace any of them.
+func ConfigureFromEnv(db *gorm.DB) {
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+
+		RegisterChannel(&SMTPChannel{
+			Addr:     host + ":" + port,
+			Host:     host,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		})
+	} else {
+		RegisterChannel(LogChannel{Channel: ChannelEmail})
+	}
+
+	RegisterChannel(&SMSChannel{Provider: LogSMSProvider{}})
+	RegisterChannel(&InAppChannel{DB: db})
+}
+
+type SMTPChannel struct {
+	Addr     string
+	Host     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s *SMTPChannel) Name() string { return ChannelEmail }
+
+func (s *SMTPChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	if recipient.Email == "" {
+		return nil
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	headers := []string{
+		"From: " + s.From,
+		"To: " + recipient.Email,
+		"Subject: " + mime.QEncoding.Encode("utf-8", message.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	}
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(message.Body, "\n", "\r\n")
+
+	return smtp.SendMail(s.Addr, auth, s.From, []string{recipient.Email}, []byte(body))
+}
+
+// SMSProvider is implemented by SMS gateway clients.
+type SMSProvider interface {
+	SendSMS(ctx context.Context, to string, body string) error
+}
+
+type SMSChannel struct {
+	Provider SMSProvider
+}
+
+func (s *SMSChannel) Name() string { return ChannelSMS }
+
+func (s *SMSChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	if recipient.Phone == "" {
+		return nil
+	}
+
+	return s.Provider.SendSMS(ctx, recipient.Phone, message.Short)
+}
+
+type LogSMSProvider struct{}
+
+func (LogSMSProvider) SendSMS(ctx context.Context, to string, body string) error {
+	log.Printf("sms to %s: %s", to, body)
+	return nil
+}
+
+// InAppChannel stores the notification in the user's inbox.
+type InAppChannel struct {
+	DB *gorm.DB
+}
+
+func (s *InAppChannel) Name() string { return ChannelInApp }
+
+func (s *InAppChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	return s.DB.WithContext(ctx).Create(&models.Notification{
+		UserType: recipient.UserType,
+		UserID:   recipient.UserID,
+		Kind:     message.Kind,
+		Title:    message.Subject,
+		Body:     message.Body,
+		Data:     encodeData(message.Data),
+	}).Error
+}
+
+type LogChannel struct {
+	Channel string
+}
+
+func (l LogChannel) Name() string { return l.Channel }
+
+func (l LogChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	log.Printf("%s notification to %s %d: %s", l.Channel, recipient.UserType, recipient.UserID, message.Subject)
+	return nil
+}
+
+type CapturedMessage struct {
+	Recipient Recipient
+	Message   Message
+}
+
+// CaptureChannel records messages instead of sending them, for tests:
+//
+//	capture := notifications.NewCaptureChannel(notifications.ChannelEmail)
+//	notifications.RegisterChannel(capture)
+type CaptureChannel struct {
+	channel  string
+	mu       sync.Mutex
+	messages []CapturedMessage
+	err      error
+}
+
+func NewCaptureChannel(channel string) *CaptureChannel {
+	return &CaptureChannel{channel: channel}
+}
+
+func (c *CaptureChannel) Name() string { return c.channel }
+
+func (c *CaptureChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	c.messages = append(c.messages, CapturedMessage{Recipient: recipient, Message: message})
+	return nil
+}
+
+// FailWith makes later sends return err, or succeed again when err is nil.
+func (c *CaptureChannel) FailWith(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.err = err
+}
+
+func (c *CaptureChannel) Messages() []CapturedMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]CapturedMessage(nil), c.messages...)
+}
+
+func (c *CaptureChannel) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messages = nil
+	c.err = nil
+}
@@ -0,0 +1,145 @@
+package notifications
+
+import (
+	"api/events"
+	"api/models"
+	"api/utils"
+	"encoding/json"
+	"gorm.io/gorm"
+)
+
+// FromEvent works out who should hear about an order event and returns a
+// request per recipient and enabled channel. Events that concern nobody
+// return no requests.
+func FromEvent(db *gorm.DB, event events.Event) ([]Request, error) {
+	switch event.Type {
+	case events.TypeOrderPlaced:
+		var placed events.OrderPlacedV1
+		if err := json.Unmarshal(event.Data, &placed); err != nil {
+			return nil, err
+		}
+		return orderPlacedRequests(db, placed)
+
+	case events.TypeSubOrderStatusChanged:
+		var changed events.SubOrderStatusChangedV1
+		if err := json.Unmarshal(event.Data, &changed); err != nil {
+			return nil, err
+		}
+		return subOrderStatusRequests(db, changed)
+
+	case events.TypeOrderItemStatusChanged:
+		var changed events.OrderItemStatusChangedV1
+		if err := json.Unmarshal(event.Data, &changed); err != nil {
+			return nil, err
+		}
+
+		// Cancellations have their own event and message.
+		if changed.Item.Status != utils.StatusShipped && changed.Item.Status != utils.StatusDelivered {
+			return nil, nil
+		}
+
+		return customerRequests(db, changed.OrderID, KindOrderItemStatus, map[string]interface{}{
+			"order_id":     changed.OrderID,
+			"product_name": changed.Item.Name,
+			"status":       changed.Item.Status,
+		})
+
+	case events.TypeOrderItemCancelled:
+		var cancelled events.OrderItemCancelledV1
+		if err := json.Unmarshal(event.Data, &cancelled); err != nil {
+			return nil, err
+		}
+
+		return customerRequests(db, cancelled.OrderID, KindOrderItemCancelled, map[string]interface{}{
+			"order_id":     cancelled.OrderID,
+			"product_name": cancelled.Item.Name,
+			"quantity":     cancelled.Item.Quantity,
+		})
+	}
+
+	return nil, nil
+}
+
+func orderPlacedRequests(db *gorm.DB, placed events.OrderPlacedV1) ([]Request, error) {
+	var items []map[string]interface{}
+	for _, item := range placed.Items {
+		items = append(items, map[string]interface{}{"name": item.Name, "sku": item.SKU, "quantity": item.Quantity, "unit_price": item.UnitPrice})
+	}
+
+	requests, err := Requests(db, "customer", placed.CustomerID, KindOrderPlaced, map[string]interface{}{
+		"order_id":   placed.OrderID,
+		"total":      placed.TotalAmount,
+		"item_count": len(placed.Items),
+		"items":      items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subOrder := range placed.SubOrders {
+		var sellerItems []map[string]interface{}
+		for _, item := range placed.Items {
+			if item.SellerID == subOrder.SellerID {
+				sellerItems = append(sellerItems, map[string]interface{}{"name": item.Name, "sku": item.SKU, "quantity": item.Quantity, "unit_price": item.UnitPrice})
+			}
+		}
+
+		sellerRequests, err := Requests(db, "seller", subOrder.SellerID, KindSellerNewOrder, map[string]interface{}{
+			"order_id":     placed.OrderID,
+			"sub_order_id": subOrder.ID,
+			"subtotal":     subOrder.Subtotal,
+			"item_count":   len(sellerItems),
+			"items":        sellerItems,
+		})
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, sellerRequests...)
+	}
+
+	return requests, nil
+}
+
+func subOrderStatusRequests(db *gorm.DB, changed events.SubOrderStatusChangedV1) ([]Request, error) {
+	var kind string
+	switch changed.SubOrder.Status {
+	case utils.StatusShipped:
+		kind = KindOrderShipped
+	case utils.StatusDelivered:
+		kind = KindOrderDelivered
+	case utils.StatusCancelled:
+		kind = KindOrderCancelled
+	default:
+		return nil, nil
+	}
+
+	var subOrder models.SubOrder
+	if err := db.Preload("Seller").First(&subOrder, changed.SubOrder.ID).Error; err != nil {
+		return nil, err
+	}
+
+	storeName := ""
+	if subOrder.Seller != nil {
+		storeName = subOrder.Seller.StoreName
+	}
+
+	return customerRequests(db, changed.OrderID, kind, map[string]interface{}{
+		"order_id":        changed.OrderID,
+		"store_name":      storeName,
+		"carrier":         subOrder.Carrier,
+		"tracking_number": subOrder.TrackingNumber,
+	})
+}
+
+func customerRequests(db *gorm.DB, orderID uint, kind string, data map[string]interface{}) ([]Request, error) {
+	var order models.Order
+	if err := db.Preload("Cart").First(&order, orderID).Error; err != nil {
+		return nil, err
+	}
+
+	if order.Cart == nil || order.Cart.CustomerID == nil {
+		return nil, nil
+	}
+
+	return Requests(db, "customer", *order.Cart.CustomerID, kind, data)
+}
@@ -0,0 +1,233 @@
+package notifications
+
+import (
+	"api/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+	"sync"
+)
+
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelInApp = "in_app"
+)
+
+const (
+	KindOrderPlaced        = "order_placed"
+	KindSellerNewOrder     = "seller_new_order"
+	KindOrderShipped       = "order_shipped"
+	KindOrderDelivered     = "order_delivered"
+	KindOrderCancelled     = "order_cancelled"
+	KindOrderItemStatus    = "order_item_status"
+	KindOrderItemCancelled = "order_item_cancelled"
+	KindCartReminder       = "cart_reminder"
+	KindPriceDrop          = "price_drop"
+)
+
+var ErrUnknownRecipient = errors.New("unknown notification recipient")
+
+type Recipient struct {
+	UserType string
+	UserID   uint
+	Name     string
+	Email    string
+	Phone    string
+	Locale   string
+}
+
+// Message is a rendered notification. Body is the long form used by email
+// and the in-app inbox; Short fits in an SMS.
+type Message struct {
+	Kind    string
+	Subject string
+	Body    string
+	Short   string
+	Data    map[string]interface{}
+}
+
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, recipient Recipient, message Message) error
+}
+
+var (
+	channelsMu sync.RWMutex
+	channels   = map[string]Channel{}
+)
+
+// RegisterChannel installs the adapter for a channel, replacing the
+// previous one with the same name.
+func RegisterChannel(channel Channel) {
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+
+	channels[channel.Name()] = channel
+}
+
+func channelFor(name string) (Channel, bool) {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+
+	channel, ok := channels[name]
+	return channel, ok
+}
+
+// Request is one notification for one user on one channel. Requests are
+// stored as job payloads, so Data must survive a JSON round trip.
+type Request struct {
+	Kind     string                 `json:"kind"`
+	UserType string                 `json:"user_type"`
+	UserID   uint                   `json:"user_id"`
+	Channel  string                 `json:"channel"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+// DefaultPreferences apply to users who never saved their own: email and
+// in-app notifications on, SMS off.
+func DefaultPreferences(userType string, userID uint) models.NotificationPreference {
+	return models.NotificationPreference{
+		UserType:     userType,
+		UserID:       userID,
+		Locale:       DefaultLocale,
+		EmailEnabled: true,
+		InAppEnabled: true,
+	}
+}
+
+func Preferences(db *gorm.DB, userType string, userID uint) (models.NotificationPreference, error) {
+	preference := DefaultPreferences(userType, userID)
+	err := db.Where("user_type = ? AND user_id = ?", userType, userID).First(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return preference, nil
+	}
+
+	return preference, err
+}
+
+func Muted(preference models.NotificationPreference, kind string) bool {
+	for _, muted := range strings.Split(preference.MutedKinds, ",") {
+		if muted == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Requests expands a notification into one request per channel the user
+// has enabled, or none if they muted this kind.
+func Requests(db *gorm.DB, userType string, userID uint, kind string, data map[string]interface{}) ([]Request, error) {
+	preference, err := Preferences(db, userType, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if Muted(preference, kind) {
+		return nil, nil
+	}
+
+	enabled := []struct {
+		channel string
+		on      bool
+	}{
+		{ChannelEmail, preference.EmailEnabled},
+		{ChannelSMS, preference.SMSEnabled},
+		{ChannelInApp, preference.InAppEnabled},
+	}
+
+	var requests []Request
+	for _, option := range enabled {
+		if option.on {
+			requests = append(requests, Request{Kind: kind, UserType: userType, UserID: userID, Channel: option.channel, Data: data})
+		}
+	}
+
+	return requests, nil
+}
+
+// Send renders the request in the user's locale and hands it to the
+// channel adapter.
+func Send(ctx context.Context, db *gorm.DB, request Request) error {
+	channel, ok := channelFor(request.Channel)
+	if !ok {
+		return fmt.Errorf("no adapter registered for notification channel %q", request.Channel)
+	}
+
+	recipient, err := LoadRecipient(db, request.UserType, request.UserID)
+	if err != nil {
+		return err
+	}
+
+	message, err := Render(request.Kind, recipient.Locale, withRecipient(request.Data, recipient))
+	if err != nil {
+		return err
+	}
+
+	return channel.Send(ctx, recipient, message)
+}
+
+func LoadRecipient(db *gorm.DB, userType string, userID uint) (Recipient, error) {
+	var user models.User
+	switch userType {
+	case "customer":
+		var customer models.Customer
+		if err := db.First(&customer, userID).Error; err != nil {
+			return Recipient{}, err
+		}
+		user = customer.User
+	case "seller":
+		var seller models.Seller
+		if err := db.First(&seller, userID).Error; err != nil {
+			return Recipient{}, err
+		}
+		user = seller.User
+	default:
+		return Recipient{}, ErrUnknownRecipient
+	}
+
+	preference, err := Preferences(db, userType, userID)
+	if err != nil {
+		return Recipient{}, err
+	}
+
+	return Recipient{
+		UserType: userType,
+		UserID:   userID,
+		Name:     user.Name,
+		Email:    user.Email,
+		Phone:    user.Phone,
+		Locale:   preference.Locale,
+	}, nil
+}
+
+func withRecipient(data map[string]interface{}, recipient Recipient) map[string]interface{} {
+	merged := map[string]interface{}{"recipient_name": recipient.Name}
+	for key, value := range data {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+func encodeData(data map[string]interface{}) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(encoded)
+}
+
+func UnreadCount(db *gorm.DB, userType string, userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&models.Notification{}).
+		Where("user_type = ? AND user_id = ? AND read_at IS NULL", userType, userID).
+		Count(&count).Error
+
+	return count, err
+}
@@ -0,0 +1,122 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// DefaultLocale is used for users without a preference and for kinds that
+// have no translation in the user's locale.
+const DefaultLocale = "en"
+
+// Each template file defines three blocks: "subject", "body" and "sms".
+// Files live at templates/<locale>/<kind>.tmpl.
+//
+//go:embed templates
+var templateFiles embed.FS
+
+var (
+	templatesOnce sync.Once
+	templates     map[string]map[string]*template.Template
+	templatesErr  error
+)
+
+var templateFuncs = template.FuncMap{
+	"money": func(value interface{}) string {
+		switch number := value.(type) {
+		case float64:
+			return fmt.Sprintf("%.2f", number)
+		case int:
+			return fmt.Sprintf("%d.00", number)
+		default:
+			return fmt.Sprint(value)
+		}
+	},
+}
+
+func loadTemplates() {
+	templates = map[string]map[string]*template.Template{}
+	templatesErr = fs.WalkDir(templateFiles, "templates", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || path.Ext(name) != ".tmpl" {
+			return err
+		}
+
+		locale := path.Base(path.Dir(name))
+		kind := strings.TrimSuffix(path.Base(name), ".tmpl")
+
+		parsed, err := template.New(kind).Funcs(templateFuncs).ParseFS(templateFiles, name)
+		if err != nil {
+			return err
+		}
+
+		if templates[locale] == nil {
+			templates[locale] = map[string]*template.Template{}
+		}
+		templates[locale][kind] = parsed
+
+		return nil
+	})
+}
+
+// Locales lists the locales that have templates.
+func Locales() []string {
+	templatesOnce.Do(loadTemplates)
+
+	var locales []string
+	for locale := range templates {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+func SupportsLocale(locale string) bool {
+	templatesOnce.Do(loadTemplates)
+
+	_, ok := templates[locale]
+	return ok
+}
+
+// Render executes the kind's template in locale, falling back to
+// DefaultLocale.
+func Render(kind string, locale string, data map[string]interface{}) (Message, error) {
+	templatesOnce.Do(loadTemplates)
+	if templatesErr != nil {
+		return Message{}, templatesErr
+	}
+
+	tmpl, ok := templates[locale][kind]
+	if !ok {
+		tmpl, ok = templates[DefaultLocale][kind]
+	}
+	if !ok {
+		return Message{}, fmt.Errorf("no notification template for %q", kind)
+	}
+
+	message := Message{Kind: kind, Data: data}
+	blocks := []struct {
+		name   string
+		target *string
+	}{
+		{"subject", &message.Subject},
+		{"body", &message.Body},
+		{"sms", &message.Short},
+	}
+	for _, block := range blocks {
+		var buffer bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buffer, block.name, data); err != nil {
+			return Message{}, err
+		}
+		*block.target = strings.TrimSpace(buffer.String())
+	}
+
+	return message, nil
+}
@@ -0,0 +1,115 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderUsesRequestedLocale(t *testing.T) {
+	message, err := Render("order_placed", "ar", map[string]interface{}{
+		"order_id":       42,
+		"recipient_name": "Sara",
+		"total":          99.5,
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	if !strings.Contains(message.Subject, "تم تأكيد الطلب") {
+		t.Errorf("subject = %q, want the Arabic template", message.Subject)
+	}
+	if message.Short != "تم تأكيد الطلب رقم 42. الإجمالي 99.50." {
+		t.Errorf("sms = %q", message.Short)
+	}
+}
+
+func TestRenderFallsBackToDefaultLocale(t *testing.T) {
+	data := map[string]interface{}{
+		"order_id":       7,
+		"recipient_name": "Sam",
+		"product_name":   "Lamp",
+		"status":         "Shipped",
+	}
+
+	fallback, err := Render("order_item_status", "fr", data)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	english, err := Render("order_item_status", DefaultLocale, data)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	if fallback.Subject != english.Subject || fallback.Body != english.Body || fallback.Short != english.Short {
+		t.Errorf("unsupported locale rendered %+v, want %+v", fallback, english)
+	}
+	if fallback.Subject != "Update on your order #7" {
+		t.Errorf("subject = %q", fallback.Subject)
+	}
+	if fallback.Body != "Hi Sam,\n\nLamp in order #7 is now Shipped." {
+		t.Errorf("body = %q", fallback.Body)
+	}
+}
+
+func TestRenderFormatsMoneyAndItems(t *testing.T) {
+	message, err := Render("order_placed", DefaultLocale, map[string]interface{}{
+		"order_id":       3,
+		"recipient_name": "Ali",
+		"total":          15,
+		"items": []map[string]interface{}{
+			{"quantity": 2, "name": "Mug", "unit_price": 7.5},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	if !strings.Contains(message.Body, "- 2 x Mug (7.50)") {
+		t.Errorf("body is missing the item line: %q", message.Body)
+	}
+	if !strings.Contains(message.Body, "Total: 15.00") {
+		t.Errorf("body is missing the total: %q", message.Body)
+	}
+}
+
+func TestRenderUnknownKind(t *testing.T) {
+	if _, err := Render("no_such_kind", DefaultLocale, nil); err == nil {
+		t.Error("expected an error for a kind without a template")
+	}
+}
+
+func TestLocalesHaveEveryDefaultTemplate(t *testing.T) {
+	templatesOnce.Do(loadTemplates)
+	if templatesErr != nil {
+		t.Fatalf("loading templates: %v", templatesErr)
+	}
+
+	for _, locale := range Locales() {
+		for kind := range templates[DefaultLocale] {
+			if _, ok := templates[locale][kind]; !ok {
+				t.Errorf("locale %s has no %s template", locale, kind)
+			}
+		}
+	}
+}
+
+func TestRenderPriceDrop(t *testing.T) {
+	message, err := Render(KindPriceDrop, DefaultLocale, map[string]interface{}{
+		"recipient_name": "Mona",
+		"product_name":   "Kettle",
+		"wishlist_name":  "Kitchen",
+		"old_price":      40,
+		"price":          32.5,
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	if message.Subject != "Kettle is now cheaper" {
+		t.Errorf("subject = %q", message.Subject)
+	}
+	if message.Short != "Kettle dropped from 40.00 to 32.50." {
+		t.Errorf("sms = %q", message.Short)
+	}
+}
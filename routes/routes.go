@@ -8,6 +8,7 @@ import (
 
 func SetupRouter(group string) *gin.Engine {
 	router := gin.Default()
+	router.Use(middlewares.RequestIDMiddleware(), middlewares.AuditMiddleware())
 
 	apiGroup := router.Group("/api")
 	{
@@ -31,22 +32,77 @@ func SetupRouter(group string) *gin.Engine {
 func CustomerRoutes(apiGroup *gin.RouterGroup) *gin.RouterGroup {
 	apiGroup.POST("/customers", controllers.CreateCustomer)
 
+	apiGroup.GET("/wishlists/shared/:token", controllers.GetSharedWishlist)
+	apiGroup.GET("/stores/:slug", controllers.GetStore)
+
+	guestGroup := apiGroup.Group("/guest")
+	{
+		guestGroup.GET("/products", controllers.GetProducts)
+		guestGroup.GET("/products/:id", controllers.GetProduct)
+		guestGroup.GET("/products/:id/reviews", controllers.GetProductReviews)
+
+		guestCartGroup := guestGroup.Group("/cart")
+		{
+			guestCartGroup.GET("/", controllers.GetCart)
+			guestCartGroup.POST("/", controllers.AddItemToCart)
+			guestCartGroup.PATCH("cart-items/:cartItemId", controllers.UpdateCartItem)
+			guestCartGroup.DELETE("cart-items/:cartItemId", controllers.DeleteCartItem)
+		}
+	}
+
 	customerGroup := apiGroup.Group("/customers")
 	customerGroup.Use(middlewares.AuthMiddleware(), middlewares.CustomerMiddleware())
 	{
 		customerGroup.GET("/profile", controllers.GetCustomerProfile)
 
+		customerGroup.GET("/store-credit", controllers.GetStoreCredit)
+		customerGroup.POST("/gift-cards/redeem", controllers.RedeemGiftCard)
+
+		addressGroup := customerGroup.Group("/addresses")
+		{
+			addressGroup.GET("/", controllers.GetCustomerAddresses)
+			addressGroup.POST("/", controllers.CreateCustomerAddress)
+			addressGroup.GET("/:id", controllers.GetCustomerAddress)
+			addressGroup.PATCH("/:id", controllers.UpdateCustomerAddress)
+			addressGroup.DELETE("/:id", controllers.DeleteCustomerAddress)
+		}
+
 		productGroup := customerGroup.Group("/products")
 		{
 			productGroup.GET("/", controllers.GetProducts)
 			productGroup.GET("/:id", controllers.GetProduct)
+			productGroup.GET("/:id/reviews", controllers.GetProductReviews)
+			productGroup.POST("/:id/reviews", controllers.CreateReview)
+		}
+
+		reviewGroup := customerGroup.Group("/reviews")
+		{
+			reviewGroup.PATCH("/:id", controllers.UpdateReview)
+			reviewGroup.DELETE("/:id", controllers.DeleteReview)
 		}
 
 		cartGroup := customerGroup.Group("/cart")
 		{
+			cartGroup.GET("/", controllers.GetCart)
 			cartGroup.POST("/", controllers.AddItemToCart)
+			cartGroup.GET("/shipping-quote", controllers.GetCartShippingQuote)
+			cartGroup.POST("/coupon", controllers.ApplyCartCoupon)
+			cartGroup.DELETE("/coupon", controllers.RemoveCartCoupon)
 			cartGroup.PATCH("cart-items/:cartItemId", controllers.UpdateCartItem)
 			cartGroup.DELETE("cart-items/:cartItemId", controllers.DeleteCartItem)
+			cartGroup.POST("cart-items/:cartItemId/save-for-later", controllers.SaveCartItemForLater)
+		}
+
+		wishlistGroup := customerGroup.Group("/wishlists")
+		{
+			wishlistGroup.GET("/", controllers.GetWishlists)
+			wishlistGroup.POST("/", controllers.CreateWishlist)
+			wishlistGroup.GET("/:id", controllers.GetWishlist)
+			wishlistGroup.PATCH("/:id", controllers.UpdateWishlist)
+			wishlistGroup.DELETE("/:id", controllers.DeleteWishlist)
+			wishlistGroup.POST("/:id/items", controllers.AddWishlistItem)
+			wishlistGroup.DELETE("/:id/items/:itemId", controllers.DeleteWishlistItem)
+			wishlistGroup.POST("/:id/items/:itemId/move-to-cart", controllers.MoveWishlistItemToCart)
 		}
 
 		orderGroup := customerGroup.Group("/orders")
@@ -54,6 +110,21 @@ func CustomerRoutes(apiGroup *gin.RouterGroup) *gin.RouterGroup {
 			orderGroup.POST("/", controllers.PlaceOrder)
 			orderGroup.GET("/", controllers.GetCustomerOrders)
 			orderGroup.GET("/:id", controllers.GetCustomerOrder)
+			orderGroup.GET("/:id/tracking", controllers.GetCustomerOrderTracking)
+		}
+
+		customerGroup.POST("sub-orders/:id/rating", controllers.RateSubOrderSeller)
+
+		customerGroup.GET("notification-preferences/", controllers.GetNotificationPreferences)
+		customerGroup.PUT("notification-preferences/", controllers.UpdateNotificationPreferences)
+
+		notificationGroup := customerGroup.Group("/notifications")
+		{
+			notificationGroup.GET("/", controllers.GetNotifications)
+			notificationGroup.GET("/unread-count", controllers.GetUnreadNotificationCount)
+			notificationGroup.GET("/stream", controllers.StreamNotifications)
+			notificationGroup.POST("/read-all", controllers.MarkAllNotificationsRead)
+			notificationGroup.POST("/:id/read", controllers.MarkNotificationRead)
 		}
 	}
 
@@ -70,16 +141,48 @@ func SellerRoutes(apiGroup *gin.RouterGroup) *gin.RouterGroup {
 		sellerGroup.GET("profile/", controllers.GetSellerProfile)
 		sellerGroup.PATCH("profile/", controllers.UpdateSellerProfile)
 
-		productGroup := sellerGroup.Group("/products")
+		applicationGroup := sellerGroup.Group("/application")
+		{
+			applicationGroup.GET("/", controllers.GetSellerApplication)
+			applicationGroup.PUT("/", controllers.SubmitSellerApplication)
+			applicationGroup.POST("/documents", controllers.UploadSellerDocument)
+		}
+
+		sellerGroup.GET("balance/", controllers.GetSellerBalance)
+		sellerGroup.GET("ledger/", controllers.GetSellerLedger)
+		sellerGroup.GET("payouts/", controllers.GetSellerPayouts)
+
+		sellerGroup.GET("notification-preferences/", controllers.GetNotificationPreferences)
+		sellerGroup.PUT("notification-preferences/", controllers.UpdateNotificationPreferences)
+
+		notificationGroup := sellerGroup.Group("/notifications")
+		{
+			notificationGroup.GET("/", controllers.GetNotifications)
+			notificationGroup.GET("/unread-count", controllers.GetUnreadNotificationCount)
+			notificationGroup.GET("/stream", controllers.StreamNotifications)
+			notificationGroup.POST("/read-all", controllers.MarkAllNotificationsRead)
+			notificationGroup.POST("/:id/read", controllers.MarkNotificationRead)
+		}
+
+		approvedGroup := sellerGroup.Group("")
+		approvedGroup.Use(middlewares.ApprovedSellerMiddleware())
+
+		productGroup := approvedGroup.Group("/products")
 		{
 			productGroup.POST("/", controllers.CreateProduct)
 			productGroup.GET("/", controllers.GetSellerProducts)
+			productGroup.GET("/export", controllers.ExportProducts)
+			productGroup.POST("/imports", controllers.CreateProductImport)
+			productGroup.GET("/imports", controllers.GetProductImports)
+			productGroup.GET("/imports/:id", controllers.GetProductImport)
 			productGroup.GET("/:id", controllers.GetSellerProductDetails)
 			productGroup.PATCH("/:id", controllers.UpdateProduct)
 			productGroup.DELETE("/:id", controllers.DeleteProduct)
+			productGroup.POST("/:id/submit", controllers.SubmitProduct)
+			productGroup.POST("/:id/archive", controllers.ArchiveProduct)
 		}
 
-		orderGroup := sellerGroup.Group("/orders")
+		orderGroup := approvedGroup.Group("/orders")
 		{
 			orderGroup.GET("/", controllers.GetSellerOrders)
 			orderGroup.GET("/:id", controllers.GetSellerOrderDetails)
@@ -87,6 +190,55 @@ func SellerRoutes(apiGroup *gin.RouterGroup) *gin.RouterGroup {
 			orderGroup.DELETE("/:id", controllers.DeleteOrder)
 			orderGroup.GET("/:id/shipping_info", controllers.GetSellerOrderShippingInfo)
 		}
+
+		subOrderGroup := approvedGroup.Group("/sub-orders")
+		{
+			subOrderGroup.GET("/", controllers.GetSellerSubOrders)
+			subOrderGroup.GET("/:id", controllers.GetSellerSubOrder)
+			subOrderGroup.PATCH("/:id", controllers.UpdateSubOrderStatus)
+			subOrderGroup.GET("/:id/shipments", controllers.GetSubOrderShipments)
+			subOrderGroup.POST("/:id/shipments", controllers.CreateShipment)
+		}
+
+		approvedGroup.POST("shipments/:id/deliver", controllers.MarkShipmentDelivered)
+
+		reviewGroup := approvedGroup.Group("/reviews")
+		{
+			reviewGroup.GET("/", controllers.GetSellerReviews)
+			reviewGroup.POST("/:id/reply", controllers.ReplyToReview)
+		}
+
+		shippingZoneGroup := approvedGroup.Group("/shipping-zones")
+		{
+			shippingZoneGroup.GET("/", controllers.GetShippingZones)
+			shippingZoneGroup.POST("/", controllers.CreateShippingZone)
+			shippingZoneGroup.PATCH("/:id", controllers.UpdateShippingZone)
+			shippingZoneGroup.DELETE("/:id", controllers.DeleteShippingZone)
+			shippingZoneGroup.POST("/:id/rates", controllers.CreateShippingRate)
+			shippingZoneGroup.DELETE("/:id/rates/:rateId", controllers.DeleteShippingRate)
+		}
+
+		webhookGroup := approvedGroup.Group("/webhooks")
+		{
+			webhookGroup.GET("/", controllers.GetWebhookEndpoints)
+			webhookGroup.POST("/", controllers.CreateWebhookEndpoint)
+			webhookGroup.GET("/:id", controllers.GetWebhookEndpoint)
+			webhookGroup.PATCH("/:id", controllers.UpdateWebhookEndpoint)
+			webhookGroup.DELETE("/:id", controllers.DeleteWebhookEndpoint)
+			webhookGroup.GET("/:id/deliveries", controllers.GetWebhookDeliveries)
+			webhookGroup.POST("/:id/test", controllers.SendTestWebhook)
+		}
+
+		promotionGroup := approvedGroup.Group("/promotions")
+		{
+			promotionGroup.GET("/", controllers.GetPromotions)
+			promotionGroup.POST("/", controllers.CreatePromotion)
+			promotionGroup.GET("/:id", controllers.GetPromotion)
+			promotionGroup.PATCH("/:id", controllers.UpdatePromotion)
+			promotionGroup.DELETE("/:id", controllers.DeletePromotion)
+			promotionGroup.POST("/:id/coupons", controllers.CreateCoupon)
+			promotionGroup.DELETE("/:id/coupons/:couponId", controllers.DeactivateCoupon)
+		}
 	}
 
 	return sellerGroup
@@ -106,6 +258,8 @@ func AdminRoutes(apiGroup *gin.RouterGroup) *gin.RouterGroup {
 			customerGroup.GET("/:id", controllers.GetCustomer)
 			customerGroup.PATCH("/:id", controllers.UpdateCustomer)
 			customerGroup.DELETE("/:id", controllers.DeleteCustomer)
+			customerGroup.GET("/deleted", controllers.GetDeletedCustomers)
+			customerGroup.POST("/:id/restore", controllers.RestoreCustomer)
 		}
 
 		sellerGroup := adminGroup.Group("/sellers")
@@ -114,21 +268,106 @@ func AdminRoutes(apiGroup *gin.RouterGroup) *gin.RouterGroup {
 			sellerGroup.GET("/:id", controllers.GetSeller)
 			sellerGroup.PATCH("/:id", controllers.UpdateSeller)
 			sellerGroup.DELETE("/:id", controllers.DeleteSeller)
+			sellerGroup.GET("/deleted", controllers.GetDeletedSellers)
+			sellerGroup.POST("/:id/restore", controllers.RestoreSeller)
+			sellerGroup.PATCH("/:id/status", controllers.UpdateSellerStatus)
+		}
+
+		applicationGroup := adminGroup.Group("/seller-applications")
+		{
+			applicationGroup.GET("/", controllers.GetSellerApplications)
+			applicationGroup.GET("/:id", controllers.GetSellerApplicationForReview)
+			applicationGroup.GET("/:id/documents/:documentId", controllers.DownloadSellerDocument)
+			applicationGroup.POST("/:id/approve", controllers.ApproveSellerApplication)
+			applicationGroup.POST("/:id/reject", controllers.RejectSellerApplication)
 		}
 
 		productGroup := adminGroup.Group("/products")
 		{
 			productGroup.GET("/", controllers.GetProducts)
 			productGroup.GET("/:id", controllers.GetProduct)
+			productGroup.GET("/moderation", controllers.GetProductModerationQueue)
+			productGroup.GET("/deleted", controllers.GetDeletedProducts)
+			productGroup.POST("/:id/restore", controllers.RestoreProduct)
+			productGroup.POST("/:id/approve", controllers.ApproveProduct)
+			productGroup.POST("/:id/reject", controllers.RejectProduct)
+		}
+
+		reviewGroup := adminGroup.Group("/reviews")
+		{
+			reviewGroup.GET("/", controllers.GetReviews)
+			reviewGroup.PATCH("/:id/moderation", controllers.ModerateReview)
 		}
 
 		orderGroup := adminGroup.Group("/orders")
 		{
 			orderGroup.GET("/", controllers.GetOrders)
+			orderGroup.GET("/deleted", controllers.GetDeletedOrders)
 			orderGroup.GET("/:id", controllers.GetOrder)
+			orderGroup.POST("/:id/restore", controllers.RestoreOrder)
 			orderGroup.GET("/:id/shipping_info", controllers.GetOrderShippingInfo)
 			orderGroup.GET("/:id/payment", controllers.GetOrderPayment)
-			orderGroup.PATCH("/:id/payment", controllers.UpdatePaymentStatus)
+			orderGroup.GET("/:id/payment/transactions", controllers.GetOrderPaymentTransactions)
+			orderGroup.POST("/:id/payment/authorize", controllers.AuthorizePayment)
+			orderGroup.POST("/:id/payment/capture", controllers.CapturePayment)
+			orderGroup.POST("/:id/payment/refund", controllers.RefundPayment)
+			orderGroup.POST("/:id/payment/chargeback", controllers.ChargebackPayment)
+		}
+
+		promotionGroup := adminGroup.Group("/promotions")
+		{
+			promotionGroup.GET("/", controllers.GetPromotions)
+			promotionGroup.POST("/", controllers.CreatePromotion)
+			promotionGroup.GET("/:id", controllers.GetPromotion)
+			promotionGroup.PATCH("/:id", controllers.UpdatePromotion)
+			promotionGroup.DELETE("/:id", controllers.DeletePromotion)
+			promotionGroup.POST("/:id/coupons", controllers.CreateCoupon)
+			promotionGroup.DELETE("/:id/coupons/:couponId", controllers.DeactivateCoupon)
+		}
+
+		giftCardGroup := adminGroup.Group("/gift-cards")
+		{
+			giftCardGroup.GET("/", controllers.GetGiftCards)
+			giftCardGroup.POST("/", controllers.IssueGiftCard)
+			giftCardGroup.GET("/:id", controllers.GetGiftCard)
+		}
+
+		taxRateGroup := adminGroup.Group("/tax-rates")
+		{
+			taxRateGroup.GET("/", controllers.GetTaxRates)
+			taxRateGroup.POST("/", controllers.CreateTaxRate)
+			taxRateGroup.PATCH("/:id", controllers.UpdateTaxRate)
+			taxRateGroup.DELETE("/:id", controllers.DeleteTaxRate)
+		}
+
+		commissionGroup := adminGroup.Group("/commissions")
+		{
+			commissionGroup.GET("/", controllers.GetCommissionRules)
+			commissionGroup.POST("/", controllers.CreateCommissionRule)
+			commissionGroup.PATCH("/:id", controllers.UpdateCommissionRule)
+			commissionGroup.DELETE("/:id", controllers.DeleteCommissionRule)
+		}
+
+		auditLogGroup := adminGroup.Group("/audit-logs")
+		{
+			auditLogGroup.GET("/", controllers.GetAuditLogs)
+			auditLogGroup.GET("/export", controllers.ExportAuditLogs)
+			auditLogGroup.GET("/:id", controllers.GetAuditLog)
+		}
+
+		jobGroup := adminGroup.Group("/jobs")
+		{
+			jobGroup.GET("/", controllers.GetJobs)
+			jobGroup.GET("/:id", controllers.GetJob)
+			jobGroup.POST("/:id/retry", controllers.RetryJob)
+		}
+
+		payoutGroup := adminGroup.Group("/payouts")
+		{
+			payoutGroup.GET("/", controllers.GetPayoutBatches)
+			payoutGroup.POST("/", controllers.CreatePayoutBatch)
+			payoutGroup.GET("/:id", controllers.GetPayoutBatch)
+			payoutGroup.POST("/:id/complete", controllers.CompletePayoutBatch)
 		}
 	}
 
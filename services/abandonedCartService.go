@@ -0,0 +1,97 @@
+package services
+
+import (
+	"api/models"
+	"gorm.io/gorm"
+	"log"
+	"sync"
+	"time"
+)
+
+type AbandonedCartReminder struct {
+	Cart     models.Cart
+	Customer models.Customer
+	Items    []models.CartItem
+	IdleFor  time.Duration
+}
+
+type CartReminderNotifier interface {
+	NotifyAbandonedCart(reminder AbandonedCartReminder) error
+}
+
+var (
+	cartReminderNotifierMu sync.RWMutex
+	cartReminderNotifier   CartReminderNotifier = LogCartReminderNotifier{}
+)
+
+func SetCartReminderNotifier(notifier CartReminderNotifier) {
+	cartReminderNotifierMu.Lock()
+	defer cartReminderNotifierMu.Unlock()
+
+	cartReminderNotifier = notifier
+}
+
+type LogCartReminderNotifier struct{}
+
+func (LogCartReminderNotifier) NotifyAbandonedCart(reminder AbandonedCartReminder) error {
+	log.Printf("cart %d of customer %s idle for %s with %d items", reminder.Cart.ID, reminder.Customer.Email, reminder.IdleFor.Round(time.Minute), len(reminder.Items))
+	return nil
+}
+
+// RemindAbandonedCarts notifies customers whose active cart has items and
+// has not changed for idleAfter. A cart is reminded once per period of
+// inactivity; any later change to the cart makes it eligible again.
+func RemindAbandonedCarts(db *gorm.DB, idleAfter time.Duration, now time.Time) (int, error) {
+	var carts []models.Cart
+	err := db.Preload("Customer").
+		Where("is_active = ? AND customer_id IS NOT NULL AND updated_at < ?", true, now.Add(-idleAfter)).
+		Where("reminded_at IS NULL OR reminded_at < updated_at").
+		Where("EXISTS (SELECT 1 FROM cart_items WHERE cart_items.cart_id = carts.id AND cart_items.deleted_at IS NULL)").
+		Find(&carts).Error
+	if err != nil {
+		return 0, err
+	}
+
+	cartReminderNotifierMu.RLock()
+	notifier := cartReminderNotifier
+	cartReminderNotifierMu.RUnlock()
+
+	reminded := 0
+	for _, cart := range carts {
+		items, _, err := LoadCartItems(db, cart.ID)
+		if err != nil {
+			return reminded, err
+		}
+
+		if err := notifier.NotifyAbandonedCart(AbandonedCartReminder{
+			Cart:     cart,
+			Customer: cart.Customer,
+			Items:    items,
+			IdleFor:  now.Sub(cart.UpdatedAt),
+		}); err != nil {
+			log.Printf("Could not send reminder for cart %d: %v", cart.ID, err)
+			continue
+		}
+
+		// UpdateColumn keeps updated_at untouched so the reminder itself
+		// does not count as cart activity.
+		if err := db.Model(&cart).UpdateColumn("reminded_at", now).Error; err != nil {
+			return reminded, err
+		}
+		reminded++
+	}
+
+	return reminded, nil
+}
+
+// ExpireAbandonedCarts deactivates customer and guest carts that have not
+// changed for expireAfter. Carts don't reserve stock, so there is nothing to
+// release.
+func ExpireAbandonedCarts(db *gorm.DB, expireAfter time.Duration, now time.Time) (int64, error) {
+	result := db.Model(&models.Cart{}).
+		Where("is_active = ? AND updated_at < ?", true, now.Add(-expireAfter)).
+		Where("NOT EXISTS (SELECT 1 FROM orders WHERE orders.cart_id = carts.id)").
+		Update("is_active", false)
+
+	return result.RowsAffected, result.Error
+}
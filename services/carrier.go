@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrUnknownCarrier = errors.New("unknown carrier")
+
+type TrackingUpdate struct {
+	Status      string
+	Description string
+	Location    string
+	OccurredAt  time.Time
+}
+
+type Carrier interface {
+	Code() string
+	Track(trackingNumber string) ([]TrackingUpdate, error)
+}
+
+var (
+	carriersMu sync.RWMutex
+	carriers   = map[string]Carrier{}
+)
+
+// Carriers without a tracking integration are listed in SHIPPING_CARRIERS
+// (comma-separated codes) and registered as ManualCarrier, as is "manual".
+func init() {
+	RegisterCarrier(ManualCarrier{code: "manual"})
+	for _, code := range strings.Split(os.Getenv("SHIPPING_CARRIERS"), ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			RegisterCarrier(ManualCarrier{code: code})
+		}
+	}
+
+	if os.Getenv("ENABLE_FAKE_CARRIER") == "true" {
+		RegisterCarrier(NewFakeCarrier())
+	}
+}
+
+func RegisterCarrier(carrier Carrier) {
+	carriersMu.Lock()
+	defer carriersMu.Unlock()
+
+	carriers[strings.ToLower(carrier.Code())] = carrier
+}
+
+func GetCarrier(code string) (Carrier, error) {
+	carriersMu.RLock()
+	defer carriersMu.RUnlock()
+
+	carrier, ok := carriers[strings.ToLower(code)]
+	if !ok {
+		return nil, ErrUnknownCarrier
+	}
+
+	return carrier, nil
+}
+
+// ManualCarrier is a carrier with no tracking API; its shipments are
+// marked delivered by the seller.
+type ManualCarrier struct {
+	code string
+}
+
+func (m ManualCarrier) Code() string {
+	return m.code
+}
+
+func (m ManualCarrier) Track(trackingNumber string) ([]TrackingUpdate, error) {
+	return nil, nil
+}
+
+type FakeCarrier struct {
+	mu      sync.Mutex
+	updates map[string][]TrackingUpdate
+}
+
+func NewFakeCarrier() *FakeCarrier {
+	return &FakeCarrier{updates: map[string][]TrackingUpdate{}}
+}
+
+func (f *FakeCarrier) Code() string {
+	return "fake"
+}
+
+func (f *FakeCarrier) AddUpdate(trackingNumber string, update TrackingUpdate) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.updates[trackingNumber] = append(f.updates[trackingNumber], update)
+}
+
+func (f *FakeCarrier) Track(trackingNumber string) ([]TrackingUpdate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	updates := make([]TrackingUpdate, len(f.updates[trackingNumber]))
+	copy(updates, f.updates[trackingNumber])
+
+	return updates, nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"os"
+	"time"
+)
+
+var ErrGuestCart = errors.New("cart does not belong to a customer")
+
+// TouchCart marks the cart as active now. Cart item writes don't update the
+// cart row on their own, and abandoned cart detection goes by updated_at.
+func TouchCart(tx *gorm.DB, cartID uint) error {
+	return tx.Model(&models.Cart{}).Where("id = ?", cartID).Update("updated_at", time.Now()).Error
+}
+
+func CartMergeStrategy() string {
+	switch strategy := os.Getenv("CART_MERGE_STRATEGY"); strategy {
+	case utils.CartMergeMax, utils.CartMergeGuest, utils.CartMergeCustomer:
+		return strategy
+	default:
+		return utils.CartMergeSum
+	}
+}
+
+func CreateGuestCart(db *gorm.DB) (models.Cart, string, error) {
+	nonce, err := utils.RandomToken()
+	if err != nil {
+		return models.Cart{}, "", err
+	}
+
+	cart := models.Cart{
+		GuestToken: nonce,
+		IsActive:   true,
+	}
+	if err := db.Create(&cart).Error; err != nil {
+		return cart, "", err
+	}
+
+	return cart, utils.SignCartToken(cart.ID, nonce), nil
+}
+
+func FindGuestCart(db *gorm.DB, token string) (models.Cart, error) {
+	var cart models.Cart
+
+	cartID, nonce, err := utils.ParseCartToken(token)
+	if err != nil {
+		return cart, err
+	}
+
+	if err := db.Where("guest_token = ? AND customer_id IS NULL AND is_active = ?", nonce, true).First(&cart, cartID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return cart, utils.ErrInvalidCartToken
+		}
+		return cart, err
+	}
+
+	return cart, nil
+}
+
+func MergeGuestCart(db *gorm.DB, token string, customerID uint, strategy string) (models.Cart, error) {
+	var customerCart models.Cart
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		guestCart, err := FindGuestCart(tx.Clauses(clause.Locking{Strength: "UPDATE"}), token)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("customer_id = ? AND is_active = ?", customerID, true).First(&customerCart).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			guestCart.CustomerID = &customerID
+			guestCart.GuestToken = ""
+			customerCart = guestCart
+			return tx.Model(&customerCart).Select("customer_id", "guest_token").Updates(&customerCart).Error
+		}
+
+		var guestItems []models.CartItem
+		if err := tx.Where("cart_id = ?", guestCart.ID).Find(&guestItems).Error; err != nil {
+			return err
+		}
+
+		for _, guestItem := range guestItems {
+			var existing models.CartItem
+			err := tx.Where("cart_id = ? AND product_id = ?", customerCart.ID, guestItem.ProductID).First(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err := tx.Model(&guestItem).Update("cart_id", customerCart.ID).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			existing.Quantity = mergedQuantity(existing.Quantity, guestItem.Quantity, strategy)
+			if err := tx.Save(&existing).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&guestItem).Error; err != nil {
+				return err
+			}
+		}
+
+		if customerCart.CouponCode == "" && guestCart.CouponCode != "" {
+			customerCart.CouponCode = guestCart.CouponCode
+			if err := tx.Model(&customerCart).Update("coupon_code", customerCart.CouponCode).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := TouchCart(tx, customerCart.ID); err != nil {
+			return err
+		}
+
+		return tx.Delete(&guestCart).Error
+	})
+
+	return customerCart, err
+}
+
+func mergedQuantity(customerQuantity int, guestQuantity int, strategy string) int {
+	switch strategy {
+	case utils.CartMergeMax:
+		if guestQuantity > customerQuantity {
+			return guestQuantity
+		}
+		return customerQuantity
+	case utils.CartMergeGuest:
+		return guestQuantity
+	case utils.CartMergeCustomer:
+		return customerQuantity
+	default:
+		return customerQuantity + guestQuantity
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type DocumentStorage interface {
+	Save(name string, content io.Reader) (string, error)
+	Open(path string) (io.ReadCloser, error)
+}
+
+var (
+	documentStorageMu sync.RWMutex
+	documentStorage   DocumentStorage = LocalDocumentStorage{Dir: defaultUploadDir()}
+)
+
+func defaultUploadDir() string {
+	if dir := os.Getenv("UPLOAD_DIR"); dir != "" {
+		return dir
+	}
+
+	return "uploads"
+}
+
+func SetDocumentStorage(storage DocumentStorage) {
+	documentStorageMu.Lock()
+	defer documentStorageMu.Unlock()
+
+	documentStorage = storage
+}
+
+func GetDocumentStorage() DocumentStorage {
+	documentStorageMu.RLock()
+	defer documentStorageMu.RUnlock()
+
+	return documentStorage
+}
+
+type LocalDocumentStorage struct {
+	Dir string
+}
+
+func (s LocalDocumentStorage) Save(name string, content io.Reader) (string, error) {
+	path := filepath.Join(s.Dir, filepath.Clean("/"+name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, content); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func (s LocalDocumentStorage) Open(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
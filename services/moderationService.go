@@ -0,0 +1,176 @@
+package services
+
+import (
+	"api/events"
+	"api/models"
+	"api/utils"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidProductTransition = errors.New("invalid product status transition")
+
+const minCategorySampleSize = 3
+
+// BannedProductWords reads the comma-separated PRODUCT_BANNED_WORDS list.
+func BannedProductWords() []string {
+	var words []string
+	for _, word := range strings.Split(os.Getenv("PRODUCT_BANNED_WORDS"), ",") {
+		if word = strings.TrimSpace(strings.ToLower(word)); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// PriceAnomalyFactor reads PRODUCT_PRICE_ANOMALY_FACTOR, defaulting to 5:
+// prices more than five times above or below the category average are
+// flagged.
+func PriceAnomalyFactor() float64 {
+	if factor, err := strconv.ParseFloat(os.Getenv("PRODUCT_PRICE_ANOMALY_FACTOR"), 64); err == nil && factor > 1 {
+		return factor
+	}
+
+	return 5
+}
+
+// FlagProduct runs the automatic moderation rules and returns a reason for
+// every rule the product trips.
+func FlagProduct(db *gorm.DB, product models.Product) ([]string, error) {
+	var flags []string
+
+	text := strings.ToLower(product.Name + " " + product.Description)
+	for _, word := range BannedProductWords() {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(word) + `\b`).MatchString(text) {
+			flags = append(flags, fmt.Sprintf("banned word: %s", word))
+		}
+	}
+
+	if product.Price <= 0 {
+		flags = append(flags, "price is not positive")
+		return flags, nil
+	}
+
+	if product.Category == "" {
+		return flags, nil
+	}
+
+	var stats struct {
+		Average float64
+		Count   int
+	}
+	if err := db.Model(&models.Product{}).
+		Select("COALESCE(AVG(price), 0) AS average, COUNT(*) AS count").
+		Where("category = ? AND status = ? AND id <> ?", product.Category, utils.ProductStatusPublished, product.ID).
+		Scan(&stats).Error; err != nil {
+		return flags, err
+	}
+
+	factor := PriceAnomalyFactor()
+	if stats.Count >= minCategorySampleSize && (product.Price > stats.Average*factor || product.Price < stats.Average/factor) {
+		flags = append(flags, fmt.Sprintf("price %.2f is far from the %s average of %.2f", product.Price, product.Category, stats.Average))
+	}
+
+	return flags, nil
+}
+
+// SubmitProductForReview runs the flagging rules and queues the product
+// for moderation.
+func SubmitProductForReview(db *gorm.DB, product *models.Product) error {
+	switch product.Status {
+	case "", utils.ProductStatusDraft, utils.ProductStatusRejected, utils.ProductStatusArchived, utils.ProductStatusPendingReview:
+	default:
+		return ErrInvalidProductTransition
+	}
+
+	flags, err := FlagProduct(db, *product)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	product.Status = utils.ProductStatusPendingReview
+	product.ModerationFlags = strings.Join(flags, "; ")
+	product.ModerationReason = ""
+	product.SubmittedAt = &now
+
+	return nil
+}
+
+// RecheckPublishedProduct sends a published product back to review when an
+// edit trips one of the flagging rules.
+func RecheckPublishedProduct(db *gorm.DB, product *models.Product) error {
+	if product.Status != utils.ProductStatusPublished {
+		return nil
+	}
+
+	flags, err := FlagProduct(db, *product)
+	if err != nil || len(flags) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	product.Status = utils.ProductStatusPendingReview
+	product.ModerationFlags = strings.Join(flags, "; ")
+	product.SubmittedAt = &now
+
+	return nil
+}
+
+// ReviewProduct must run in a transaction; the row lock makes a concurrent
+// review of the same product wait and then fail the transition check.
+func ReviewProduct(tx *gorm.DB, productID string, adminID uint, approve bool, reason string) (models.Product, error) {
+	var product models.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error; err != nil {
+		return product, err
+	}
+
+	if product.Status != utils.ProductStatusPendingReview {
+		return product, ErrInvalidProductTransition
+	}
+
+	now := time.Now()
+	product.Status = utils.ProductStatusRejected
+	if approve {
+		product.Status = utils.ProductStatusPublished
+	}
+	product.ModerationReason = reason
+	product.ReviewedAt = &now
+	product.ReviewedBy = &adminID
+
+	if err := tx.Model(&product).Select("status", "moderation_reason", "reviewed_at", "reviewed_by").Updates(&product).Error; err != nil {
+		return product, err
+	}
+
+	updated := events.ProductUpdatedV1{Product: events.NewProduct(product), PreviousPrice: product.Price}
+	return product, events.Record(tx, "product", product.ID, updated, product.SellerId)
+}
+
+// ArchiveProduct takes a published or rejected product off the catalog.
+func ArchiveProduct(tx *gorm.DB, product *models.Product) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(product, product.ID).Error; err != nil {
+		return err
+	}
+
+	switch product.Status {
+	case utils.ProductStatusPublished, utils.ProductStatusRejected:
+	default:
+		return ErrInvalidProductTransition
+	}
+
+	product.Status = utils.ProductStatusArchived
+	if err := tx.Model(product).Update("status", product.Status).Error; err != nil {
+		return err
+	}
+
+	updated := events.ProductUpdatedV1{Product: events.NewProduct(*product), PreviousPrice: product.Price}
+	return events.Record(tx, "product", product.ID, updated, product.SellerId)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"api/events"
+	"api/models"
+	"api/utils"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNoActiveCart       = errors.New("there is no active cart for this customer")
+	ErrCartAlreadyOrdered = errors.New("there is an already placed order for this customer's cart")
+)
+
+// LockActiveCart locks the customer's active cart for checkout, so two
+// checkouts of the same cart run one after the other.
+func LockActiveCart(tx *gorm.DB, customerID uint) (models.Cart, error) {
+	var cart models.Cart
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("customer_id = ? AND is_active = ?", customerID, true).First(&cart).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return cart, ErrNoActiveCart
+		}
+		return cart, err
+	}
+
+	var count int64
+	if err := tx.Model(&models.Order{}).Where("cart_id = ?", cart.ID).Count(&count).Error; err != nil {
+		return cart, err
+	}
+	if count > 0 {
+		return cart, ErrCartAlreadyOrdered
+	}
+
+	return cart, nil
+}
+
+func SplitOrder(tx *gorm.DB, order models.Order, cartItems []models.CartItem, address models.AddressFields, shipping map[uint]ShippingOption, shippingDiscounts map[uint]float64) ([]models.SubOrder, error) {
+	var subOrders []models.SubOrder
+	indexBySeller := map[uint]int{}
+
+	for _, item := range cartItems {
+		index, ok := indexBySeller[item.Product.SellerId]
+		if !ok {
+			subOrder := models.SubOrder{
+				OrderID:  order.ID,
+				SellerID: item.Product.SellerId,
+				Status:   utils.StatusPending,
+			}
+			if option, ok := shipping[item.Product.SellerId]; ok {
+				if option.RateID != 0 {
+					rateID := option.RateID
+					subOrder.ShippingRateID = &rateID
+				}
+				subOrder.ShippingMethod = option.Name
+				subOrder.ShippingCost = option.Cost
+			}
+			subOrder.DiscountAmount = shippingDiscounts[item.Product.SellerId]
+			subOrders = append(subOrders, subOrder)
+			index = len(subOrders) - 1
+			indexBySeller[item.Product.SellerId] = index
+		}
+
+		subOrders[index].Subtotal += float64(item.Quantity) * item.Product.Price
+		subOrders[index].TaxAmount += item.TaxAmount
+		subOrders[index].DiscountAmount += item.DiscountAmount
+		subOrders[index].CartItems = append(subOrders[index].CartItems, item)
+	}
+
+	for i := range subOrders {
+		items := subOrders[i].CartItems
+		subOrders[i].CartItems = nil
+		subOrders[i].Subtotal = utils.RoundMoney(subOrders[i].Subtotal)
+		subOrders[i].TaxAmount = utils.RoundMoney(subOrders[i].TaxAmount)
+		subOrders[i].DiscountAmount = utils.RoundMoney(subOrders[i].DiscountAmount)
+
+		if err := tx.Create(&subOrders[i]).Error; err != nil {
+			return nil, err
+		}
+
+		for j := range items {
+			items[j].SubOrderID = &subOrders[i].ID
+			items[j].Status = utils.StatusPending
+
+			if err := tx.Model(&items[j]).Select("sub_order_id", "status", "unit_price", "discount_amount", "tax_rate", "tax_amount", "tax_inclusive").Updates(&items[j]).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		shippingInfo := models.ShippingInfo{
+			OrderID:         order.ID,
+			SubOrderID:      &subOrders[i].ID,
+			Address:         utils.FormatAddress(address),
+			ShippingAddress: address,
+		}
+		if err := tx.Create(&shippingInfo).Error; err != nil {
+			return nil, err
+		}
+
+		subOrders[i].CartItems = items
+		subOrders[i].ShippingInfo = &shippingInfo
+	}
+
+	return subOrders, nil
+}
+
+func RefreshOrderStatus(tx *gorm.DB, orderID uint) error {
+	var statuses []string
+	if err := tx.Model(&models.SubOrder{}).Where("order_id = ?", orderID).Pluck("status", &statuses).Error; err != nil {
+		return err
+	}
+
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	return tx.Model(&models.Order{}).Where("id = ?", orderID).Update("status", combinedStatus(statuses)).Error
+}
+
+func RefreshSubOrderStatus(tx *gorm.DB, subOrderID uint) error {
+	var statuses []string
+	if err := tx.Model(&models.CartItem{}).Where("sub_order_id = ?", subOrderID).Pluck("status", &statuses).Error; err != nil {
+		return err
+	}
+
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	var subOrder models.SubOrder
+	if err := tx.First(&subOrder, subOrderID).Error; err != nil {
+		return err
+	}
+
+	previousStatus := subOrder.Status
+	if err := tx.Model(&subOrder).Update("status", combinedStatus(statuses)).Error; err != nil {
+		return err
+	}
+
+	if err := events.RecordSubOrderStatusChange(tx, subOrder, previousStatus); err != nil {
+		return err
+	}
+
+	return RefreshOrderStatus(tx, subOrder.OrderID)
+}
+
+// combinedStatus derives a parent's status from its children's, ignoring
+// cancelled children unless all of them are.
+func combinedStatus(statuses []string) string {
+	counts := map[string]int{}
+	for _, status := range statuses {
+		counts[status]++
+	}
+
+	active := len(statuses) - counts[utils.StatusCancelled]
+	switch {
+	case active == 0:
+		return utils.StatusCancelled
+	case counts[utils.StatusDelivered] == active:
+		return utils.StatusDelivered
+	case counts[utils.StatusShipped]+counts[utils.StatusDelivered] == active:
+		return utils.StatusShipped
+	case counts[utils.StatusShipped]+counts[utils.StatusDelivered]+counts[utils.StatusPartiallyShipped] > 0:
+		return utils.StatusPartiallyShipped
+	}
+
+	return utils.StatusPending
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"api/utils"
+	"testing"
+)
+
+func TestCombinedStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{"all pending", []string{utils.StatusPending, utils.StatusPending}, utils.StatusPending},
+		{"one shipped", []string{utils.StatusShipped, utils.StatusPending}, utils.StatusPartiallyShipped},
+		{"one delivered", []string{utils.StatusDelivered, utils.StatusPending}, utils.StatusPartiallyShipped},
+		{"shipped and delivered", []string{utils.StatusShipped, utils.StatusDelivered}, utils.StatusShipped},
+		{"all delivered", []string{utils.StatusDelivered, utils.StatusDelivered}, utils.StatusDelivered},
+		{"cancelled and delivered", []string{utils.StatusCancelled, utils.StatusDelivered}, utils.StatusDelivered},
+		{"cancelled and pending", []string{utils.StatusCancelled, utils.StatusPending}, utils.StatusPending},
+		{"cancelled, shipped and pending", []string{utils.StatusCancelled, utils.StatusShipped, utils.StatusPending}, utils.StatusPartiallyShipped},
+		{"partially shipped child", []string{utils.StatusPartiallyShipped, utils.StatusPending}, utils.StatusPartiallyShipped},
+		{"partially shipped and shipped children", []string{utils.StatusPartiallyShipped, utils.StatusShipped}, utils.StatusPartiallyShipped},
+		{"all cancelled", []string{utils.StatusCancelled, utils.StatusCancelled}, utils.StatusCancelled},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := combinedStatus(c.statuses); got != c.want {
+				t.Errorf("combinedStatus(%v) = %s, want %s", c.statuses, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"api/events"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"testing"
+)
+
+func TestRefreshSubOrderStatus(t *testing.T) {
+	db := testDB(t)
+
+	seller := createSeller(t, db, "status-seller")
+	customer := createCustomer(t, db, "status-customer")
+	first := createProduct(t, db, seller, "first")
+	second := createProduct(t, db, seller, "second")
+	cart, items := createCart(t, db, customer, true, first, second)
+
+	var order models.Order
+	if err := db.Where("cart_id = ?", cart.ID).First(&order).Error; err != nil {
+		t.Fatalf("loading order: %v", err)
+	}
+
+	subOrder := models.SubOrder{OrderID: order.ID, SellerID: seller.ID, Status: utils.StatusPending}
+	mustCreate(t, db, &subOrder)
+	if err := db.Model(&models.CartItem{}).Where("cart_id = ?", cart.ID).Update("sub_order_id", subOrder.ID).Error; err != nil {
+		t.Fatalf("assigning items: %v", err)
+	}
+
+	steps := []struct {
+		first  string
+		second string
+		want   string
+	}{
+		{utils.StatusShipped, utils.StatusPending, utils.StatusPartiallyShipped},
+		{utils.StatusDelivered, utils.StatusShipped, utils.StatusShipped},
+		{utils.StatusDelivered, utils.StatusCancelled, utils.StatusDelivered},
+	}
+
+	for _, step := range steps {
+		db.Model(&items[0]).Update("status", step.first)
+		db.Model(&items[1]).Update("status", step.second)
+
+		if err := services.RefreshSubOrderStatus(db, subOrder.ID); err != nil {
+			t.Fatalf("RefreshSubOrderStatus: %v", err)
+		}
+
+		var refreshed models.SubOrder
+		db.First(&refreshed, subOrder.ID)
+		if refreshed.Status != step.want {
+			t.Errorf("items %s/%s: sub-order status = %s, want %s", step.first, step.second, refreshed.Status, step.want)
+		}
+
+		var refreshedOrder models.Order
+		db.First(&refreshedOrder, order.ID)
+		if refreshedOrder.Status != step.want {
+			t.Errorf("items %s/%s: order status = %s, want %s", step.first, step.second, refreshedOrder.Status, step.want)
+		}
+	}
+
+	var recorded int64
+	db.Model(&models.OutboxEvent{}).Where("aggregate_type = ? AND aggregate_id = ? AND type = ?", "order", order.ID, events.TypeSubOrderStatusChanged).Count(&recorded)
+	if recorded != int64(len(steps)) {
+		t.Errorf("recorded %d status change events, want %d", recorded, len(steps))
+	}
+
+	if err := services.RefreshSubOrderStatus(db, subOrder.ID); err != nil {
+		t.Fatalf("RefreshSubOrderStatus: %v", err)
+	}
+	db.Model(&models.OutboxEvent{}).Where("aggregate_type = ? AND aggregate_id = ? AND type = ?", "order", order.ID, events.TypeSubOrderStatusChanged).Count(&recorded)
+	if recorded != int64(len(steps)) {
+		t.Error("refreshing an unchanged sub-order recorded an event")
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidPaymentAmount      = errors.New("amount must be greater than zero")
+	ErrInvalidTransactionType    = errors.New("invalid payment transaction type")
+	ErrAuthorizationExceedsTotal = errors.New("authorization exceeds the outstanding order amount")
+	ErrCaptureExceedsAuthorized  = errors.New("capture exceeds the uncaptured authorized amount for this method")
+	ErrRefundExceedsCaptured     = errors.New("amount exceeds the captured amount left for this method")
+)
+
+func CalculatePaymentBalance(payment models.Payment) models.PaymentBalance {
+	var balance models.PaymentBalance
+	for _, transaction := range payment.Transactions {
+		switch transaction.Type {
+		case utils.PaymentTransactionAuthorization:
+			balance.Authorized += transaction.Amount
+		case utils.PaymentTransactionCapture:
+			balance.Captured += transaction.Amount
+		case utils.PaymentTransactionRefund:
+			balance.Refunded += transaction.Amount
+		case utils.PaymentTransactionChargeback:
+			balance.ChargedBack += transaction.Amount
+		}
+	}
+
+	balance.Authorized = utils.RoundMoney(balance.Authorized)
+	balance.Captured = utils.RoundMoney(balance.Captured)
+	balance.Refunded = utils.RoundMoney(balance.Refunded)
+	balance.ChargedBack = utils.RoundMoney(balance.ChargedBack)
+	balance.Net = utils.RoundMoney(balance.Captured - balance.Refunded - balance.ChargedBack)
+	balance.Outstanding = utils.RoundMoney(payment.TotalAmount - balance.Net)
+
+	return balance
+}
+
+func LoadPayment(db *gorm.DB, orderID interface{}) (models.Payment, error) {
+	var payment models.Payment
+	if err := db.Preload("Transactions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).Where("order_id = ?", orderID).First(&payment).Error; err != nil {
+		return payment, err
+	}
+
+	balance := CalculatePaymentBalance(payment)
+	payment.Balance = &balance
+
+	return payment, nil
+}
+
+func RecordPaymentTransaction(db *gorm.DB, orderID interface{}, transactionType string, method string, amount float64, reference string) (models.Payment, error) {
+	var payment models.Payment
+
+	amount = utils.RoundMoney(amount)
+	if amount <= 0 {
+		return payment, ErrInvalidPaymentAmount
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).First(&payment).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("payment_id = ?", payment.ID).Find(&payment.Transactions).Error; err != nil {
+			return err
+		}
+
+		if err := checkPaymentTransaction(payment, transactionType, method, amount); err != nil {
+			return err
+		}
+
+		transaction := models.PaymentTransaction{
+			PaymentID: payment.ID,
+			Type:      transactionType,
+			Method:    method,
+			Amount:    amount,
+			Reference: reference,
+		}
+
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+
+		payment.Transactions = append(payment.Transactions, transaction)
+		balance := CalculatePaymentBalance(payment)
+		payment.Status = paymentStatus(balance)
+		payment.Paid = balance.Outstanding <= 0 && balance.Net > 0
+
+		if err := tx.Model(&payment).Select("status", "paid").Updates(&payment).Error; err != nil {
+			return err
+		}
+
+		if transactionType == utils.PaymentTransactionRefund || transactionType == utils.PaymentTransactionChargeback {
+			return ReverseSettlement(tx, payment, transactionType, amount)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return payment, err
+	}
+
+	return LoadPayment(db, orderID)
+}
+
+func checkPaymentTransaction(payment models.Payment, transactionType string, method string, amount float64) error {
+	balance := CalculatePaymentBalance(payment)
+	methodBalance := CalculatePaymentBalance(models.Payment{Transactions: transactionsForMethod(payment.Transactions, method)})
+
+	switch transactionType {
+	case utils.PaymentTransactionAuthorization:
+		pending := balance.Authorized - balance.Captured
+		if utils.RoundMoney(pending+amount) > balance.Outstanding {
+			return ErrAuthorizationExceedsTotal
+		}
+	case utils.PaymentTransactionCapture:
+		if amount > utils.RoundMoney(methodBalance.Authorized-methodBalance.Captured) {
+			return ErrCaptureExceedsAuthorized
+		}
+	case utils.PaymentTransactionRefund, utils.PaymentTransactionChargeback:
+		if amount > methodBalance.Net {
+			return ErrRefundExceedsCaptured
+		}
+	default:
+		return ErrInvalidTransactionType
+	}
+
+	return nil
+}
+
+func transactionsForMethod(transactions []models.PaymentTransaction, method string) []models.PaymentTransaction {
+	var filtered []models.PaymentTransaction
+	for _, transaction := range transactions {
+		if transaction.Method == method {
+			filtered = append(filtered, transaction)
+		}
+	}
+
+	return filtered
+}
+
+func paymentStatus(balance models.PaymentBalance) string {
+	switch {
+	case balance.Captured == 0 && balance.Authorized > 0:
+		return utils.PaymentStatusAuthorized
+	case balance.Captured == 0:
+		return utils.PaymentStatusUnpaid
+	case balance.Net <= 0:
+		return utils.PaymentStatusRefunded
+	case balance.Refunded > 0 || balance.ChargedBack > 0:
+		return utils.PaymentStatusPartiallyRefunded
+	case balance.Outstanding > 0:
+		return utils.PaymentStatusPartiallyPaid
+	default:
+		return utils.PaymentStatusPaid
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"testing"
+)
+
+func transaction(transactionType string, method string, amount float64) models.PaymentTransaction {
+	return models.PaymentTransaction{Type: transactionType, Method: method, Amount: amount}
+}
+
+func TestCheckPaymentTransaction(t *testing.T) {
+	authorized := []models.PaymentTransaction{
+		transaction(utils.PaymentTransactionAuthorization, "card", 60),
+	}
+	captured := []models.PaymentTransaction{
+		transaction(utils.PaymentTransactionAuthorization, "card", 60),
+		transaction(utils.PaymentTransactionCapture, "card", 60),
+		transaction(utils.PaymentTransactionAuthorization, "paypal", 40),
+		transaction(utils.PaymentTransactionCapture, "paypal", 40),
+		transaction(utils.PaymentTransactionRefund, "paypal", 15),
+	}
+
+	cases := []struct {
+		name            string
+		transactions    []models.PaymentTransaction
+		transactionType string
+		method          string
+		amount          float64
+		want            error
+	}{
+		{"authorize the full total", nil, utils.PaymentTransactionAuthorization, "card", 100, nil},
+		{"authorize more than the total", nil, utils.PaymentTransactionAuthorization, "card", 100.01, ErrAuthorizationExceedsTotal},
+		{"authorize the rest", authorized, utils.PaymentTransactionAuthorization, "paypal", 40, nil},
+		{"authorize past a pending authorization", authorized, utils.PaymentTransactionAuthorization, "paypal", 40.01, ErrAuthorizationExceedsTotal},
+		{"capture the authorization", authorized, utils.PaymentTransactionCapture, "card", 60, nil},
+		{"capture more than authorized", authorized, utils.PaymentTransactionCapture, "card", 60.01, ErrCaptureExceedsAuthorized},
+		{"capture another method's authorization", authorized, utils.PaymentTransactionCapture, "paypal", 10, ErrCaptureExceedsAuthorized},
+		{"refund what a method captured", captured, utils.PaymentTransactionRefund, "card", 60, nil},
+		{"refund more than a method captured", captured, utils.PaymentTransactionRefund, "card", 60.01, ErrRefundExceedsCaptured},
+		{"refund what is left after a refund", captured, utils.PaymentTransactionRefund, "paypal", 25, nil},
+		{"refund past an earlier refund", captured, utils.PaymentTransactionRefund, "paypal", 25.01, ErrRefundExceedsCaptured},
+		{"charge back what was captured", captured, utils.PaymentTransactionChargeback, "card", 60, nil},
+		{"charge back an uncaptured method", authorized, utils.PaymentTransactionChargeback, "card", 1, ErrRefundExceedsCaptured},
+		{"unknown transaction type", nil, "void", "card", 10, ErrInvalidTransactionType},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			payment := models.Payment{TotalAmount: 100, Transactions: c.transactions}
+			if got := checkPaymentTransaction(payment, c.transactionType, c.method, c.amount); !errors.Is(got, c.want) {
+				t.Errorf("checkPaymentTransaction = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestPaymentStatus(t *testing.T) {
+	cases := []struct {
+		name         string
+		transactions []models.PaymentTransaction
+		want         string
+	}{
+		{"nothing recorded", nil, utils.PaymentStatusUnpaid},
+		{"authorized only", []models.PaymentTransaction{
+			transaction(utils.PaymentTransactionAuthorization, "card", 100),
+		}, utils.PaymentStatusAuthorized},
+		{"partly captured", []models.PaymentTransaction{
+			transaction(utils.PaymentTransactionAuthorization, "card", 100),
+			transaction(utils.PaymentTransactionCapture, "card", 40),
+		}, utils.PaymentStatusPartiallyPaid},
+		{"fully captured", []models.PaymentTransaction{
+			transaction(utils.PaymentTransactionAuthorization, "card", 100),
+			transaction(utils.PaymentTransactionCapture, "card", 100),
+		}, utils.PaymentStatusPaid},
+		{"captured in cents that add up", []models.PaymentTransaction{
+			transaction(utils.PaymentTransactionCapture, "card", 33.33),
+			transaction(utils.PaymentTransactionCapture, "card", 33.33),
+			transaction(utils.PaymentTransactionCapture, "card", 33.34),
+		}, utils.PaymentStatusPaid},
+		{"partly refunded", []models.PaymentTransaction{
+			transaction(utils.PaymentTransactionCapture, "card", 100),
+			transaction(utils.PaymentTransactionRefund, "card", 30),
+		}, utils.PaymentStatusPartiallyRefunded},
+		{"partly charged back", []models.PaymentTransaction{
+			transaction(utils.PaymentTransactionCapture, "card", 100),
+			transaction(utils.PaymentTransactionChargeback, "card", 30),
+		}, utils.PaymentStatusPartiallyRefunded},
+		{"fully refunded", []models.PaymentTransaction{
+			transaction(utils.PaymentTransactionCapture, "card", 100),
+			transaction(utils.PaymentTransactionRefund, "card", 60),
+			transaction(utils.PaymentTransactionChargeback, "card", 40),
+		}, utils.PaymentStatusRefunded},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			balance := CalculatePaymentBalance(models.Payment{TotalAmount: 100, Transactions: c.transactions})
+			if got := paymentStatus(balance); got != c.want {
+				t.Errorf("paymentStatus(%+v) = %s, want %s", balance, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrEmptyCart            = errors.New("cart has no items")
+	ErrUnavailableCartItems = errors.New("cart has items that are no longer available")
+)
+
+type PricingOptions struct {
+	CustomerID      uint
+	Address         *models.AddressFields
+	ShippingMethods map[uint]uint
+	RequireShipping bool
+	Now             time.Time
+}
+
+type PricedLine struct {
+	CartItemID   uint            `json:"cart_item_id"`
+	ProductID    uint            `json:"product_id"`
+	Product      *models.Product `json:"product"`
+	Quantity     int             `json:"quantity"`
+	UnitPrice    float64         `json:"unit_price"`
+	Subtotal     float64         `json:"subtotal"`
+	Discount     float64         `json:"discount"`
+	TaxAmount    float64         `json:"tax_amount"`
+	TaxInclusive bool            `json:"tax_inclusive"`
+	Total        float64         `json:"total"`
+}
+
+type UnavailableCartItem struct {
+	CartItemID uint   `json:"cart_item_id"`
+	ProductID  uint   `json:"product_id"`
+	Quantity   int    `json:"quantity"`
+	Reason     string `json:"reason"`
+}
+
+type PricedCart struct {
+	CartID              uint                    `json:"cart_id"`
+	CouponCode          string                  `json:"coupon_code"`
+	CouponError         string                  `json:"coupon_error,omitempty"`
+	Lines               []PricedLine            `json:"lines"`
+	Subtotal            float64                 `json:"subtotal"`
+	DiscountAmount      float64                 `json:"discount_amount"`
+	ShippingCost        float64                 `json:"shipping_cost"`
+	TaxAmount           float64                 `json:"tax_amount"`
+	Total               float64                 `json:"total"`
+	Discounts           []AppliedDiscount       `json:"discounts"`
+	Shipping            map[uint]ShippingOption `json:"shipping"`
+	ShippingUnavailable bool                    `json:"shipping_unavailable"`
+	UnavailableItems    []UnavailableCartItem   `json:"unavailable_items"`
+	ShippingDiscounts   map[uint]float64        `json:"-"`
+	Tax                 TaxResult               `json:"-"`
+	Items               []models.CartItem       `json:"-"`
+	Quotes              []SellerShippingQuote   `json:"-"`
+}
+
+// LoadCartItems splits a cart into the items that can still be bought and
+// those whose product was deleted or pulled from the catalog.
+func LoadCartItems(db *gorm.DB, cartID uint) ([]models.CartItem, []UnavailableCartItem, error) {
+	var cartItems []models.CartItem
+	if err := db.Preload("Product", IncludeDeleted).Where("cart_id = ?", cartID).Order("id").Find(&cartItems).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var productIDs []uint
+	for _, item := range cartItems {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	visible := map[uint]bool{}
+	if len(productIDs) > 0 {
+		var visibleIDs []uint
+		if err := db.Model(&models.Product{}).Scopes(VisibleProducts).Where("products.id IN ?", productIDs).Pluck("products.id", &visibleIDs).Error; err != nil {
+			return nil, nil, err
+		}
+		for _, id := range visibleIDs {
+			visible[id] = true
+		}
+	}
+
+	var available []models.CartItem
+	var unavailable []UnavailableCartItem
+	for _, item := range cartItems {
+		reason := ""
+		switch {
+		case item.Product == nil || item.Product.DeletedAt.Valid:
+			reason = "deleted"
+		case !visible[item.ProductID]:
+			reason = "unavailable"
+		}
+
+		if reason == "" {
+			available = append(available, item)
+			continue
+		}
+
+		unavailable = append(unavailable, UnavailableCartItem{
+			CartItemID: item.ID,
+			ProductID:  item.ProductID,
+			Quantity:   item.Quantity,
+			Reason:     reason,
+		})
+	}
+
+	return available, unavailable, nil
+}
+
+func PriceCart(db *gorm.DB, cart models.Cart, options PricingOptions) (PricedCart, error) {
+	priced := PricedCart{
+		CartID:     cart.ID,
+		CouponCode: cart.CouponCode,
+		Shipping:   map[uint]ShippingOption{},
+	}
+
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+
+	cartItems, unavailable, err := LoadCartItems(db, cart.ID)
+	if err != nil {
+		return priced, err
+	}
+	priced.UnavailableItems = unavailable
+
+	if options.RequireShipping && len(unavailable) > 0 {
+		return priced, ErrUnavailableCartItems
+	}
+
+	if len(cartItems) == 0 {
+		if options.RequireShipping {
+			return priced, ErrEmptyCart
+		}
+		return priced, nil
+	}
+
+	for _, item := range cartItems {
+		priced.Subtotal += float64(item.Quantity) * item.Product.Price
+	}
+
+	if options.Address != nil {
+		quotes, err := QuoteShipping(db, cartItems, options.Address.Country)
+		if err != nil {
+			return priced, err
+		}
+		priced.Quotes = quotes
+
+		shipping, err := SelectShipping(quotes, options.ShippingMethods)
+		if err != nil {
+			if options.RequireShipping || (!errors.Is(err, ErrNoShippingOption) && !errors.Is(err, ErrInvalidShippingMethod)) {
+				return priced, err
+			}
+			priced.ShippingUnavailable = true
+		} else {
+			priced.Shipping = shipping
+		}
+	}
+
+	shippingBySeller := map[uint]float64{}
+	for sellerID, option := range priced.Shipping {
+		shippingBySeller[sellerID] = option.Cost
+		priced.ShippingCost += option.Cost
+	}
+
+	priced.Discounts, err = EvaluatePromotions(db, PromotionContext{
+		CustomerID:       options.CustomerID,
+		Items:            cartItems,
+		ShippingBySeller: shippingBySeller,
+		CouponCode:       cart.CouponCode,
+		Now:              options.Now,
+	})
+	if err != nil {
+		return priced, err
+	}
+
+	lineDiscounts := DiscountsByLine(priced.Discounts)
+	priced.ShippingDiscounts = ShippingDiscountsBySeller(priced.Discounts)
+	for i := range cartItems {
+		cartItems[i].DiscountAmount = lineDiscounts[cartItems[i].ID]
+	}
+	for _, discount := range priced.Discounts {
+		priced.DiscountAmount += discount.Amount
+	}
+
+	if options.Address != nil {
+		priced.Tax, err = CalculateTax(db, CartTaxRequest(cartItems, *options.Address))
+		if err != nil {
+			return priced, err
+		}
+	}
+	ApplyTaxToCartItems(cartItems, priced.Tax)
+
+	for _, item := range cartItems {
+		subtotal := float64(item.Quantity) * item.UnitPrice
+		total := subtotal - item.DiscountAmount
+		if !item.TaxInclusive {
+			total += item.TaxAmount
+		}
+
+		priced.Lines = append(priced.Lines, PricedLine{
+			CartItemID:   item.ID,
+			ProductID:    item.ProductID,
+			Product:      item.Product,
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
+			Subtotal:     utils.RoundMoney(subtotal),
+			Discount:     item.DiscountAmount,
+			TaxAmount:    item.TaxAmount,
+			TaxInclusive: item.TaxInclusive,
+			Total:        utils.RoundMoney(total),
+		})
+	}
+
+	priced.Items = cartItems
+	priced.Subtotal = utils.RoundMoney(priced.Subtotal)
+	priced.ShippingCost = utils.RoundMoney(priced.ShippingCost)
+	priced.DiscountAmount = utils.RoundMoney(priced.DiscountAmount)
+	priced.TaxAmount = priced.Tax.TotalTax
+	priced.Total = utils.RoundMoney(priced.Subtotal + priced.ShippingCost - priced.DiscountAmount + priced.Tax.ExclusiveTax)
+
+	return priced, nil
+}
+
+// RefreshCartTotal prices the cart for display. A coupon that is no longer
+// valid is left on the cart but priced without and reported in CouponError;
+// checkout still rejects it.
+func RefreshCartTotal(db *gorm.DB, cart *models.Cart, options PricingOptions) (PricedCart, error) {
+	priced, err := PriceCart(db, *cart, options)
+	if errors.Is(err, ErrCouponNotFound) || errors.Is(err, ErrCouponUnavailable) {
+		withoutCoupon := *cart
+		withoutCoupon.CouponCode = ""
+
+		couponErr := err
+		priced, err = PriceCart(db, withoutCoupon, options)
+		priced.CouponCode = cart.CouponCode
+		priced.CouponError = couponErr.Error()
+	}
+	if err != nil {
+		return priced, err
+	}
+
+	if cart.TotalPrice == priced.Total {
+		return priced, nil
+	}
+
+	// UpdateColumn keeps updated_at untouched so viewing the cart does not
+	// count as cart activity.
+	cart.TotalPrice = priced.Total
+	if err := db.Model(cart).UpdateColumn("total_price", cart.TotalPrice).Error; err != nil {
+		return priced, err
+	}
+
+	return priced, nil
+}
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"api/events"
+	"api/models"
+	"api/utils"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const importProgressInterval = 25
+
+var ProductExportColumns = []string{"sku", "name", "description", "category", "price", "tax_class", "weight_kg", "length_cm", "width_cm", "height_cm", "status"}
+
+var errMissingSKUColumn = errors.New("header must include a sku column")
+
+type ProductRow struct {
+	SKU         string  `json:"sku"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Category    string  `json:"category"`
+	Price       float64 `json:"price"`
+	TaxClass    string  `json:"tax_class"`
+	WeightKg    float64 `json:"weight_kg"`
+	LengthCm    float64 `json:"length_cm"`
+	WidthCm     float64 `json:"width_cm"`
+	HeightCm    float64 `json:"height_cm"`
+	Status      string  `json:"status,omitempty"`
+}
+
+type ParsedProductRow struct {
+	Row  int
+	Data ProductRow
+	Err  error
+}
+
+func ProductRowFromModel(product models.Product) ProductRow {
+	return ProductRow{
+		SKU:         product.SKU,
+		Name:        product.Name,
+		Description: product.Description,
+		Category:    product.Category,
+		Price:       product.Price,
+		TaxClass:    product.TaxClass,
+		WeightKg:    product.WeightKg,
+		LengthCm:    product.LengthCm,
+		WidthCm:     product.WidthCm,
+		HeightCm:    product.HeightCm,
+		Status:      product.Status,
+	}
+}
+
+func (row ProductRow) CSVRecord() []string {
+	formatFloat := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	return []string{
+		row.SKU,
+		row.Name,
+		row.Description,
+		row.Category,
+		formatFloat(row.Price),
+		row.TaxClass,
+		formatFloat(row.WeightKg),
+		formatFloat(row.LengthCm),
+		formatFloat(row.WidthCm),
+		formatFloat(row.HeightCm),
+		row.Status,
+	}
+}
+
+// ParseProductRows reads every row of a CSV or JSON Lines file. Row
+// numbers are 1-based data rows; malformed rows carry their error instead
+// of aborting the whole file.
+func ParseProductRows(format string, reader io.Reader) ([]ParsedProductRow, error) {
+	switch format {
+	case utils.FileFormatCSV:
+		return parseProductCSV(reader)
+	case utils.FileFormatJSONL:
+		return parseProductJSONL(reader)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func parseProductCSV(reader io.Reader) ([]ParsedProductRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["sku"]; !ok {
+		return nil, errMissingSKUColumn
+	}
+
+	var rows []ParsedProductRow
+	for rowNumber := 1; ; rowNumber++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rows = append(rows, ParsedProductRow{Row: rowNumber, Err: err})
+			continue
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		parsed := ParsedProductRow{Row: rowNumber}
+		parsed.Data = ProductRow{
+			SKU:         value("sku"),
+			Name:        value("name"),
+			Description: value("description"),
+			Category:    value("category"),
+			TaxClass:    value("tax_class"),
+		}
+
+		numbers := []struct {
+			column string
+			target *float64
+		}{
+			{"price", &parsed.Data.Price},
+			{"weight_kg", &parsed.Data.WeightKg},
+			{"length_cm", &parsed.Data.LengthCm},
+			{"width_cm", &parsed.Data.WidthCm},
+			{"height_cm", &parsed.Data.HeightCm},
+		}
+		for _, number := range numbers {
+			raw := value(number.column)
+			if raw == "" {
+				continue
+			}
+
+			parsedNumber, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				parsed.Err = fmt.Errorf("%s must be a number", number.column)
+				break
+			}
+			*number.target = parsedNumber
+		}
+
+		rows = append(rows, parsed)
+	}
+
+	return rows, nil
+}
+
+func parseProductJSONL(reader io.Reader) ([]ParsedProductRow, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []ParsedProductRow
+	rowNumber := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		rowNumber++
+		parsed := ParsedProductRow{Row: rowNumber}
+		if err := json.Unmarshal([]byte(line), &parsed.Data); err != nil {
+			parsed.Err = fmt.Errorf("invalid JSON: %v", err)
+		}
+		rows = append(rows, parsed)
+	}
+
+	return rows, scanner.Err()
+}
+
+func validateProductRow(row ProductRow) error {
+	switch {
+	case row.SKU == "":
+		return errors.New("sku is required")
+	case row.Name == "":
+		return errors.New("name is required")
+	case row.Price <= 0:
+		return errors.New("price must be greater than zero")
+	case row.WeightKg < 0 || row.LengthCm < 0 || row.WidthCm < 0 || row.HeightCm < 0:
+		return errors.New("weight and dimensions cannot be negative")
+	}
+
+	return nil
+}
+
+// UpsertSellerProduct creates the product or updates the seller's product
+// with the same SKU. It reports whether a new product was created.
+func UpsertSellerProduct(db *gorm.DB, sellerID uint, row ProductRow) (bool, error) {
+	if err := validateProductRow(row); err != nil {
+		return false, err
+	}
+
+	var product models.Product
+	err := db.Where("sku = ?", row.SKU).First(&product).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if row.TaxClass == "" {
+			row.TaxClass = utils.DefaultTaxClass
+		}
+
+		product = models.Product{
+			SKU:         row.SKU,
+			Name:        row.Name,
+			Description: row.Description,
+			Category:    row.Category,
+			Price:       row.Price,
+			TaxClass:    row.TaxClass,
+			WeightKg:    row.WeightKg,
+			LengthCm:    row.LengthCm,
+			WidthCm:     row.WidthCm,
+			HeightCm:    row.HeightCm,
+			SellerId:    sellerID,
+			Status:      utils.ProductStatusDraft,
+		}
+		if err := SubmitProductForReview(db, &product); err != nil {
+			return false, err
+		}
+
+		if err := db.Create(&product).Error; err != nil {
+			return false, err
+		}
+
+		return true, events.Record(db, "product", product.ID, events.ProductCreatedV1{Product: events.NewProduct(product)}, sellerID)
+	}
+
+	if product.SellerId != sellerID {
+		return false, errors.New("sku is already used by another seller")
+	}
+
+	oldPrice := product.Price
+	product.Name = row.Name
+	product.Price = row.Price
+	if row.TaxClass != "" {
+		product.TaxClass = row.TaxClass
+	}
+	if row.Description != "" {
+		product.Description = row.Description
+	}
+	if row.Category != "" {
+		product.Category = row.Category
+	}
+	if row.WeightKg != 0 {
+		product.WeightKg = row.WeightKg
+	}
+	if row.LengthCm != 0 {
+		product.LengthCm = row.LengthCm
+	}
+	if row.WidthCm != 0 {
+		product.WidthCm = row.WidthCm
+	}
+	if row.HeightCm != 0 {
+		product.HeightCm = row.HeightCm
+	}
+
+	if err := RecheckPublishedProduct(db, &product); err != nil {
+		return false, err
+	}
+
+	if err := db.Omit("average_rating", "review_count").Save(&product).Error; err != nil {
+		return false, err
+	}
+
+	updated := events.ProductUpdatedV1{Product: events.NewProduct(product), PreviousPrice: oldPrice}
+	if err := events.Record(db, "product", product.ID, updated, sellerID); err != nil {
+		return false, err
+	}
+
+	if err := NotifyPriceDrop(db, product, oldPrice); err != nil {
+		log.Printf("Could not notify wishlist price drop for product %d: %v", product.ID, err)
+	}
+
+	return false, nil
+}
+
+// RunProductImport processes a stored import file row by row, recording
+// progress and per-row errors on the import as it goes.
+func RunProductImport(db *gorm.DB, importID uint) error {
+	var productImport models.ProductImport
+	if err := db.First(&productImport, importID).Error; err != nil {
+		return err
+	}
+
+	if productImport.Status == utils.ImportStatusCompleted {
+		return nil
+	}
+
+	now := time.Now()
+	productImport.Status = utils.ImportStatusProcessing
+	productImport.StartedAt = &now
+	productImport.ProcessedRows = 0
+	productImport.CreatedCount = 0
+	productImport.UpdatedCount = 0
+	productImport.FailedCount = 0
+	if err := db.Unscoped().Where("product_import_id = ?", productImport.ID).Delete(&models.ProductImportError{}).Error; err != nil {
+		return err
+	}
+	if err := db.Save(&productImport).Error; err != nil {
+		return err
+	}
+
+	rows, err := readProductImportRows(productImport)
+	if err != nil {
+		return finishProductImport(db, &productImport, utils.ImportStatusFailed, err.Error())
+	}
+
+	productImport.TotalRows = len(rows)
+	if err := db.Model(&productImport).Update("total_rows", productImport.TotalRows).Error; err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		rowErr := row.Err
+		if rowErr == nil {
+			var created bool
+			rowErr = db.Transaction(func(tx *gorm.DB) error {
+				var err error
+				created, err = UpsertSellerProduct(tx, productImport.SellerID, row.Data)
+				return err
+			})
+			if rowErr == nil && created {
+				productImport.CreatedCount++
+			} else if rowErr == nil {
+				productImport.UpdatedCount++
+			}
+		}
+
+		if rowErr != nil {
+			productImport.FailedCount++
+			if err := db.Create(&models.ProductImportError{
+				ProductImportID: productImport.ID,
+				Row:             row.Row,
+				SKU:             row.Data.SKU,
+				Message:         rowErr.Error(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		productImport.ProcessedRows = i + 1
+		if productImport.ProcessedRows%importProgressInterval == 0 {
+			if err := db.Model(&productImport).Select("processed_rows", "created_count", "updated_count", "failed_count").Updates(&productImport).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return finishProductImport(db, &productImport, utils.ImportStatusCompleted, "")
+}
+
+func readProductImportRows(productImport models.ProductImport) ([]ParsedProductRow, error) {
+	file, err := GetDocumentStorage().Open(productImport.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseProductRows(productImport.Format, file)
+}
+
+func finishProductImport(db *gorm.DB, productImport *models.ProductImport, status string, message string) error {
+	now := time.Now()
+	productImport.Status = status
+	productImport.Message = message
+	productImport.FinishedAt = &now
+
+	return db.Omit("Errors").Save(productImport).Error
+}
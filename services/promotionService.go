@@ -0,0 +1,357 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCouponNotFound      = errors.New("coupon code is not valid")
+	ErrCouponUnavailable   = errors.New("coupon code is no longer available")
+	ErrPromotionLimitsUsed = errors.New("promotion usage limit has been reached")
+)
+
+type PromotionContext struct {
+	CustomerID       uint
+	Items            []models.CartItem
+	ShippingBySeller map[uint]float64
+	CouponCode       string
+	Now              time.Time
+}
+
+type AppliedDiscount struct {
+	PromotionID     uint             `json:"promotion_id"`
+	CouponID        *uint            `json:"-"`
+	CouponCode      string           `json:"coupon_code,omitempty"`
+	SellerID        *uint            `json:"seller_id"`
+	Type            string           `json:"type"`
+	Description     string           `json:"description"`
+	Amount          float64          `json:"amount"`
+	LineAmounts     map[uint]float64 `json:"-"`
+	ShippingAmounts map[uint]float64 `json:"-"`
+}
+
+// FindCoupon looks up a coupon the customer may still use. A customerID of
+// zero skips the per-customer limit.
+func FindCoupon(db *gorm.DB, code string, customerID uint, now time.Time) (models.Coupon, error) {
+	var coupon models.Coupon
+	if err := db.Preload("Promotion").Where("code = ?", strings.ToUpper(strings.TrimSpace(code))).First(&coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return coupon, ErrCouponNotFound
+		}
+		return coupon, err
+	}
+
+	if !coupon.Active || (coupon.UsageLimit > 0 && coupon.UsageCount >= coupon.UsageLimit) {
+		return coupon, ErrCouponUnavailable
+	}
+
+	if coupon.Promotion == nil || !promotionIsLive(*coupon.Promotion, now) {
+		return coupon, ErrCouponUnavailable
+	}
+
+	if customerID != 0 {
+		if err := checkCouponCustomerLimit(db, coupon, customerID); err != nil {
+			return coupon, err
+		}
+	}
+
+	return coupon, nil
+}
+
+func checkCouponCustomerLimit(db *gorm.DB, coupon models.Coupon, customerID uint) error {
+	if coupon.PerCustomerLimit <= 0 {
+		return nil
+	}
+
+	var used int64
+	if err := db.Model(&models.PromotionRedemption{}).Where("coupon_id = ? AND customer_id = ?", coupon.ID, customerID).Count(&used).Error; err != nil {
+		return err
+	}
+	if int(used) >= coupon.PerCustomerLimit {
+		return ErrCouponUnavailable
+	}
+
+	return nil
+}
+
+func EvaluatePromotions(db *gorm.DB, ctx PromotionContext) ([]AppliedDiscount, error) {
+	var promotions []models.Promotion
+	if err := db.Where("active = ? AND requires_coupon = ?", true, false).Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+
+	var coupon *models.Coupon
+	if ctx.CouponCode != "" {
+		found, err := FindCoupon(db, ctx.CouponCode, ctx.CustomerID, ctx.Now)
+		if err != nil {
+			return nil, err
+		}
+		coupon = &found
+		promotions = append(promotions, *found.Promotion)
+	}
+
+	var discounts []AppliedDiscount
+	remainingLines := map[uint]float64{}
+	for _, item := range ctx.Items {
+		remainingLines[item.ID] = float64(item.Quantity) * item.Product.Price
+	}
+	remainingShipping := map[uint]float64{}
+	for sellerID, cost := range ctx.ShippingBySeller {
+		remainingShipping[sellerID] = cost
+	}
+
+	for _, promotion := range promotions {
+		if !promotionIsLive(promotion, ctx.Now) {
+			continue
+		}
+
+		if promotion.PerCustomerLimit > 0 && ctx.CustomerID != 0 {
+			var used int64
+			if err := db.Model(&models.PromotionRedemption{}).Where("promotion_id = ? AND customer_id = ?", promotion.ID, ctx.CustomerID).Count(&used).Error; err != nil {
+				return nil, err
+			}
+			if int(used) >= promotion.PerCustomerLimit {
+				continue
+			}
+		}
+
+		eligible := eligibleItems(promotion, ctx.Items)
+		if len(eligible) == 0 && promotion.Type != utils.PromotionFreeShipping {
+			continue
+		}
+
+		eligibleSubtotal := 0.0
+		for _, item := range eligible {
+			eligibleSubtotal += float64(item.Quantity) * item.Product.Price
+		}
+		if eligibleSubtotal < promotion.MinSubtotal {
+			continue
+		}
+
+		discount := AppliedDiscount{
+			PromotionID: promotion.ID,
+			SellerID:    promotion.SellerID,
+			Type:        promotion.Type,
+			Description: promotion.Name,
+		}
+		if promotion.RequiresCoupon && coupon != nil {
+			discount.CouponID = &coupon.ID
+			discount.CouponCode = coupon.Code
+		}
+
+		switch promotion.Type {
+		case utils.PromotionPercentage:
+			discount.LineAmounts = allocateProportionally(eligible, eligibleSubtotal*math.Min(promotion.Value, 100)/100, remainingLines)
+		case utils.PromotionFixed:
+			discount.LineAmounts = allocateProportionally(eligible, math.Min(promotion.Value, eligibleSubtotal), remainingLines)
+		case utils.PromotionBuyXGetY:
+			discount.LineAmounts = buyXGetYAmounts(promotion, eligible, remainingLines)
+		case utils.PromotionFreeShipping:
+			discount.ShippingAmounts = map[uint]float64{}
+			for sellerID, cost := range remainingShipping {
+				if promotion.SellerID == nil || *promotion.SellerID == sellerID {
+					discount.ShippingAmounts[sellerID] = cost
+				}
+			}
+		}
+
+		for itemID, amount := range discount.LineAmounts {
+			remainingLines[itemID] = utils.RoundMoney(remainingLines[itemID] - amount)
+			discount.Amount += amount
+		}
+		for sellerID, amount := range discount.ShippingAmounts {
+			remainingShipping[sellerID] = utils.RoundMoney(remainingShipping[sellerID] - amount)
+			discount.Amount += amount
+		}
+
+		discount.Amount = utils.RoundMoney(discount.Amount)
+		if discount.Amount > 0 {
+			discounts = append(discounts, discount)
+		}
+	}
+
+	return discounts, nil
+}
+
+func DiscountsByLine(discounts []AppliedDiscount) map[uint]float64 {
+	amounts := map[uint]float64{}
+	for _, discount := range discounts {
+		for itemID, amount := range discount.LineAmounts {
+			amounts[itemID] = utils.RoundMoney(amounts[itemID] + amount)
+		}
+	}
+
+	return amounts
+}
+
+func ShippingDiscountsBySeller(discounts []AppliedDiscount) map[uint]float64 {
+	amounts := map[uint]float64{}
+	for _, discount := range discounts {
+		for sellerID, amount := range discount.ShippingAmounts {
+			amounts[sellerID] = utils.RoundMoney(amounts[sellerID] + amount)
+		}
+	}
+
+	return amounts
+}
+
+func RedeemPromotions(tx *gorm.DB, order models.Order, customerID uint, discounts []AppliedDiscount) error {
+	for _, discount := range discounts {
+		result := tx.Model(&models.Promotion{}).
+			Where("id = ? AND (usage_limit = 0 OR usage_count < usage_limit)", discount.PromotionID).
+			Update("usage_count", gorm.Expr("usage_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: %s", ErrPromotionLimitsUsed, discount.Description)
+		}
+
+		if discount.CouponID != nil {
+			result := tx.Model(&models.Coupon{}).
+				Where("id = ? AND (usage_limit = 0 OR usage_count < usage_limit)", *discount.CouponID).
+				Update("usage_count", gorm.Expr("usage_count + 1"))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrCouponUnavailable
+			}
+
+			var coupon models.Coupon
+			if err := tx.First(&coupon, *discount.CouponID).Error; err != nil {
+				return err
+			}
+			if err := checkCouponCustomerLimit(tx, coupon, customerID); err != nil {
+				return err
+			}
+		}
+
+		var promotion models.Promotion
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promotion, discount.PromotionID).Error; err != nil {
+			return err
+		}
+		if promotion.PerCustomerLimit > 0 {
+			var used int64
+			if err := tx.Model(&models.PromotionRedemption{}).Where("promotion_id = ? AND customer_id = ?", promotion.ID, customerID).Count(&used).Error; err != nil {
+				return err
+			}
+			if int(used) >= promotion.PerCustomerLimit {
+				return fmt.Errorf("%w: %s", ErrPromotionLimitsUsed, discount.Description)
+			}
+		}
+
+		redemption := models.PromotionRedemption{
+			PromotionID: discount.PromotionID,
+			CouponID:    discount.CouponID,
+			CustomerID:  customerID,
+			OrderID:     order.ID,
+			Amount:      discount.Amount,
+		}
+		if err := tx.Create(&redemption).Error; err != nil {
+			return err
+		}
+
+		orderDiscount := models.OrderDiscount{
+			OrderID:     order.ID,
+			PromotionID: discount.PromotionID,
+			SellerID:    discount.SellerID,
+			CouponCode:  discount.CouponCode,
+			Type:        discount.Type,
+			Description: discount.Description,
+			Amount:      discount.Amount,
+		}
+		if err := tx.Create(&orderDiscount).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func promotionIsLive(promotion models.Promotion, now time.Time) bool {
+	if !promotion.Active {
+		return false
+	}
+	if promotion.StartsAt != nil && now.Before(*promotion.StartsAt) {
+		return false
+	}
+	if promotion.EndsAt != nil && now.After(*promotion.EndsAt) {
+		return false
+	}
+
+	return promotion.UsageLimit == 0 || promotion.UsageCount < promotion.UsageLimit
+}
+
+func eligibleItems(promotion models.Promotion, items []models.CartItem) []models.CartItem {
+	var eligible []models.CartItem
+	for _, item := range items {
+		if promotion.SellerID != nil && item.Product.SellerId != *promotion.SellerID {
+			continue
+		}
+		if promotion.ProductID != nil && item.ProductID != *promotion.ProductID {
+			continue
+		}
+		if promotion.Category != "" && item.Product.Category != promotion.Category {
+			continue
+		}
+
+		eligible = append(eligible, item)
+	}
+
+	return eligible
+}
+
+func allocateProportionally(items []models.CartItem, amount float64, remaining map[uint]float64) map[uint]float64 {
+	allocations := map[uint]float64{}
+
+	total := 0.0
+	for _, item := range items {
+		total += remaining[item.ID]
+	}
+	if total <= 0 || amount <= 0 {
+		return allocations
+	}
+
+	amount = math.Min(amount, total)
+	allocated := 0.0
+	for i, item := range items {
+		share := utils.RoundMoney(amount * remaining[item.ID] / total)
+		if i == len(items)-1 {
+			share = utils.RoundMoney(amount - allocated)
+		}
+		share = math.Min(share, remaining[item.ID])
+
+		allocations[item.ID] = share
+		allocated += share
+	}
+
+	return allocations
+}
+
+func buyXGetYAmounts(promotion models.Promotion, items []models.CartItem, remaining map[uint]float64) map[uint]float64 {
+	amounts := map[uint]float64{}
+	group := promotion.BuyQuantity + promotion.GetQuantity
+	if promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+		return amounts
+	}
+
+	for _, item := range items {
+		free := (item.Quantity / group) * promotion.GetQuantity
+		if free == 0 {
+			continue
+		}
+
+		amounts[item.ID] = math.Min(utils.RoundMoney(float64(free)*item.Product.Price), remaining[item.ID])
+	}
+
+	return amounts
+}
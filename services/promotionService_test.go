@@ -0,0 +1,72 @@
+package services
+
+import (
+	"api/models"
+	"reflect"
+	"testing"
+)
+
+func cartItem(id uint, quantity int, price float64) models.CartItem {
+	item := models.CartItem{ProductID: id, Quantity: quantity, Product: &models.Product{Price: price}}
+	item.ID = id
+	return item
+}
+
+func TestAllocateProportionally(t *testing.T) {
+	three := []models.CartItem{cartItem(1, 1, 10), cartItem(2, 1, 10), cartItem(3, 1, 10)}
+	two := []models.CartItem{cartItem(1, 1, 30), cartItem(2, 1, 70)}
+
+	cases := []struct {
+		name      string
+		items     []models.CartItem
+		amount    float64
+		remaining map[uint]float64
+		want      map[uint]float64
+	}{
+		{"by remaining amount", two, 10, map[uint]float64{1: 30, 2: 70}, map[uint]float64{1: 3, 2: 7}},
+		{"last share takes the rounding", three, 10, map[uint]float64{1: 10, 2: 10, 3: 10}, map[uint]float64{1: 3.33, 2: 3.33, 3: 3.34}},
+		{"rounded down shares leave more for the last", two, 0.9, map[uint]float64{1: 1, 2: 0.01}, map[uint]float64{1: 0.89, 2: 0.01}},
+		{"capped at what is left", two, 50, map[uint]float64{1: 10, 2: 20}, map[uint]float64{1: 10, 2: 20}},
+		{"already discounted item gets nothing", two, 10, map[uint]float64{1: 0, 2: 20}, map[uint]float64{1: 0, 2: 10}},
+		{"nothing left to discount", two, 10, map[uint]float64{}, map[uint]float64{}},
+		{"no amount", two, 0, map[uint]float64{1: 30, 2: 70}, map[uint]float64{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := allocateProportionally(c.items, c.amount, c.remaining)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("allocateProportionally(%.2f) = %v, want %v", c.amount, got, c.want)
+			}
+		})
+	}
+}
+
+func TestBuyXGetYAmounts(t *testing.T) {
+	items := []models.CartItem{
+		cartItem(1, 3, 10),
+		cartItem(2, 7, 5),
+		cartItem(3, 2, 20),
+		cartItem(4, 3, 10),
+	}
+	remaining := map[uint]float64{1: 30, 2: 35, 3: 40, 4: 4}
+
+	cases := []struct {
+		name      string
+		promotion models.Promotion
+		want      map[uint]float64
+	}{
+		{"buy 2 get 1", models.Promotion{BuyQuantity: 2, GetQuantity: 1}, map[uint]float64{1: 10, 2: 10, 4: 4}},
+		{"buy 1 get 1", models.Promotion{BuyQuantity: 1, GetQuantity: 1}, map[uint]float64{1: 10, 2: 15, 3: 20, 4: 4}},
+		{"missing buy quantity", models.Promotion{GetQuantity: 1}, map[uint]float64{}},
+		{"missing get quantity", models.Promotion{BuyQuantity: 2}, map[uint]float64{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := buyXGetYAmounts(c.promotion, items, remaining); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("buyXGetYAmounts = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
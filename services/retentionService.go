@@ -0,0 +1,176 @@
+package services
+
+import (
+	"api/models"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+var (
+	ErrRestoreConflict = errors.New("a live record already uses this email or phone")
+	ErrSellerDeleted   = errors.New("the product's seller is deleted")
+)
+
+// IncludeDeleted is a preload scope for records that stay part of order
+// history after being soft deleted, e.g. ordered products.
+func IncludeDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// OnlyDeleted scopes a query to soft-deleted rows.
+func OnlyDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+func RestoreCustomer(tx *gorm.DB, customer *models.Customer) error {
+	if err := checkUserConflict(tx, &models.Customer{}, customer.User); err != nil {
+		return err
+	}
+
+	return restore(tx, customer, &customer.DeletedAt)
+}
+
+// RestoreSeller brings a seller back. Store slugs of deleted sellers stay
+// reserved, so only the email and phone can clash.
+func RestoreSeller(tx *gorm.DB, seller *models.Seller) error {
+	if err := checkUserConflict(tx, &models.Seller{}, seller.User); err != nil {
+		return err
+	}
+
+	return restore(tx, seller, &seller.DeletedAt)
+}
+
+func RestoreProduct(tx *gorm.DB, product *models.Product) error {
+	var seller models.Seller
+	if err := tx.First(&seller, product.SellerId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSellerDeleted
+		}
+		return err
+	}
+
+	return restore(tx, product, &product.DeletedAt)
+}
+
+func RestoreOrder(tx *gorm.DB, order *models.Order) error {
+	return restore(tx, order, &order.DeletedAt)
+}
+
+func restore(tx *gorm.DB, record interface{}, deletedAt *gorm.DeletedAt) error {
+	if err := tx.Unscoped().Model(record).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+
+	*deletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+// checkUserConflict reports whether someone signed up with the deleted
+// user's email or phone in the meantime.
+func checkUserConflict(tx *gorm.DB, model interface{}, user models.User) error {
+	var count int64
+	if err := tx.Model(model).Where("email = ? OR phone = ?", user.Email, user.Phone).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrRestoreConflict
+	}
+
+	return nil
+}
+
+type purgeTarget struct {
+	name  string
+	model interface{}
+	// keep excludes rows that must survive, typically because order
+	// history still points at them.
+	keep string
+	// prepare removes dependent rows the database would otherwise refuse
+	// to drop along with the record.
+	prepare func(tx *gorm.DB, id uint) error
+}
+
+// Orders are financial records and are never purged, so neither are the
+// products, customers and sellers they reference.
+var purgeTargets = []purgeTarget{
+	{
+		name:  "cart_items",
+		model: &models.CartItem{},
+	},
+	{
+		name:  "carts",
+		model: &models.Cart{},
+		keep:  "EXISTS (SELECT 1 FROM orders WHERE orders.cart_id = carts.id)",
+		prepare: func(tx *gorm.DB, id uint) error {
+			return tx.Unscoped().Where("cart_id = ?", id).Delete(&models.CartItem{}).Error
+		},
+	},
+	{
+		name:  "wishlist_items",
+		model: &models.WishlistItem{},
+	},
+	{
+		name:  "products",
+		model: &models.Product{},
+		keep:  "EXISTS (SELECT 1 FROM cart_items JOIN orders ON orders.cart_id = cart_items.cart_id WHERE cart_items.product_id = products.id)",
+		prepare: func(tx *gorm.DB, id uint) error {
+			return tx.Unscoped().Where("product_id = ?", id).Delete(&models.WishlistItem{}).Error
+		},
+	},
+	{
+		name:  "customers",
+		model: &models.Customer{},
+		keep:  "EXISTS (SELECT 1 FROM carts JOIN orders ON orders.cart_id = carts.id WHERE carts.customer_id = customers.id)",
+		prepare: func(tx *gorm.DB, id uint) error {
+			return tx.Unscoped().Where("customer_id = ?", id).Delete(&models.Cart{}).Error
+		},
+	},
+	{
+		name:  "sellers",
+		model: &models.Seller{},
+		keep:  "EXISTS (SELECT 1 FROM sub_orders WHERE sub_orders.seller_id = sellers.id) OR EXISTS (SELECT 1 FROM products WHERE products.seller_id = sellers.id)",
+	},
+}
+
+// PurgeDeleted permanently removes rows soft deleted more than retention
+// ago and returns how many were removed per table. Rows still referenced
+// by other records are kept and retried on the next run.
+func PurgeDeleted(db *gorm.DB, retention time.Duration, now time.Time) (map[string]int, error) {
+	cutoff := now.Add(-retention)
+	purged := map[string]int{}
+
+	for _, target := range purgeTargets {
+		query := db.Unscoped().Model(target.model).Where("deleted_at < ?", cutoff)
+		if target.keep != "" {
+			query = query.Where("NOT (" + target.keep + ")")
+		}
+
+		var ids []uint
+		if err := query.Order("id").Pluck("id", &ids).Error; err != nil {
+			return purged, err
+		}
+
+		for _, id := range ids {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if target.prepare != nil {
+					if err := target.prepare(tx, id); err != nil {
+						return err
+					}
+				}
+
+				return tx.Unscoped().Delete(target.model, id).Error
+			})
+			if err != nil {
+				log.Printf("Keeping deleted %s %d: %v", target.name, id, err)
+				continue
+			}
+
+			purged[target.name]++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,95 @@
+package services_test
+
+import (
+	"api/models"
+	"api/services"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPurgeDeletedKeepRules(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+	expired := now.Add(-48 * time.Hour)
+	recent := now.Add(-time.Hour)
+
+	seller := createSeller(t, db, "purge-seller")
+	customer := createCustomer(t, db, "purge-customer")
+
+	ordered := createProduct(t, db, seller, "ordered")
+	unordered := createProduct(t, db, seller, "unordered")
+	recentlyDeleted := createProduct(t, db, seller, "recent")
+	_, orderedItems := createCart(t, db, customer, true, ordered)
+	createCart(t, db, customer, false, unordered)
+
+	for _, product := range []models.Product{ordered, unordered} {
+		softDeleteAt(t, db, &product, expired)
+	}
+	softDeleteAt(t, db, &recentlyDeleted, recent)
+
+	buyer := createCustomer(t, db, "purge-buyer")
+	createCart(t, db, buyer, true, recentlyDeleted)
+	softDeleteAt(t, db, &buyer, expired)
+
+	browser := createCustomer(t, db, "purge-browser")
+	browserCart, _ := createCart(t, db, browser, false, recentlyDeleted)
+	softDeleteAt(t, db, &browser, expired)
+
+	emptySeller := createSeller(t, db, "purge-empty")
+	softDeleteAt(t, db, &emptySeller, expired)
+
+	leavingSeller := createSeller(t, db, "purge-leaving")
+	leavingProduct := createProduct(t, db, leavingSeller, "leaving")
+	softDeleteAt(t, db, &leavingProduct, expired)
+	softDeleteAt(t, db, &leavingSeller, expired)
+
+	guestCart := models.Cart{IsActive: true}
+	mustCreate(t, db, &guestCart)
+	guestItem := models.CartItem{CartID: guestCart.ID, ProductID: unordered.ID, Quantity: 1}
+	mustCreate(t, db, &guestItem)
+	softDeleteAt(t, db, &guestCart, expired)
+
+	orderedCart, _ := createCart(t, db, customer, true, ordered)
+	softDeleteAt(t, db, &orderedCart, expired)
+
+	wishlist := models.Wishlist{CustomerID: customer.ID, Name: "purge", ShareToken: fmt.Sprintf("purge-%d", now.UnixNano())}
+	mustCreate(t, db, &wishlist)
+	movedItem := models.WishlistItem{WishlistID: wishlist.ID, ProductID: ordered.ID, Quantity: 1}
+	mustCreate(t, db, &movedItem)
+	softDeleteAt(t, db, &movedItem, expired)
+
+	softDeleteAt(t, db, &seller, expired)
+
+	if _, err := services.PurgeDeleted(db, 24*time.Hour, now); err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+
+	checks := []struct {
+		name  string
+		model interface{}
+		id    uint
+		kept  bool
+	}{
+		{"product on an order", &models.Product{}, ordered.ID, true},
+		{"order line of a deleted product", &models.CartItem{}, orderedItems[0].ID, true},
+		{"product only in an open cart", &models.Product{}, unordered.ID, false},
+		{"product deleted within retention", &models.Product{}, recentlyDeleted.ID, true},
+		{"customer with an order", &models.Customer{}, buyer.ID, true},
+		{"customer without orders", &models.Customer{}, browser.ID, false},
+		{"cart of a purged customer", &models.Cart{}, browserCart.ID, false},
+		{"merged guest cart", &models.Cart{}, guestCart.ID, false},
+		{"item of a merged guest cart", &models.CartItem{}, guestItem.ID, false},
+		{"deleted cart with an order", &models.Cart{}, orderedCart.ID, true},
+		{"wishlist item moved to the cart", &models.WishlistItem{}, movedItem.ID, false},
+		{"seller with products", &models.Seller{}, seller.ID, true},
+		{"seller without products", &models.Seller{}, emptySeller.ID, false},
+		{"seller whose products were purged", &models.Seller{}, leavingSeller.ID, false},
+	}
+
+	for _, check := range checks {
+		if got := exists(t, db, check.model, check.id); got != check.kept {
+			t.Errorf("%s: kept = %v, want %v", check.name, got, check.kept)
+		}
+	}
+}
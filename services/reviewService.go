@@ -0,0 +1,81 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"gorm.io/gorm"
+	"math"
+)
+
+var (
+	ErrNotVerifiedPurchase = errors.New("product has not been delivered to this customer")
+	ErrReviewExists        = errors.New("customer has already reviewed this product")
+)
+
+// VerifiedPurchaseOrder returns the order through which the product was
+// delivered to the customer.
+func VerifiedPurchaseOrder(db *gorm.DB, customerID uint, productID uint) (uint, error) {
+	var orderIDs []uint
+	err := db.Table("cart_items").
+		Select("orders.id").
+		Joins("JOIN carts ON carts.id = cart_items.cart_id").
+		Joins("JOIN orders ON orders.cart_id = carts.id AND orders.deleted_at IS NULL").
+		Where("carts.customer_id = ? AND cart_items.product_id = ? AND cart_items.status = ? AND cart_items.deleted_at IS NULL", customerID, productID, utils.StatusDelivered).
+		Order("orders.id").
+		Limit(1).
+		Pluck("orders.id", &orderIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if len(orderIDs) == 0 {
+		return 0, ErrNotVerifiedPurchase
+	}
+
+	return orderIDs[0], nil
+}
+
+func CreateReview(tx *gorm.DB, review *models.Review) error {
+	orderID, err := VerifiedPurchaseOrder(tx, review.CustomerID, review.ProductID)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&models.Review{}).Where("product_id = ? AND customer_id = ?", review.ProductID, review.CustomerID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrReviewExists
+	}
+
+	review.OrderID = orderID
+	if err := tx.Create(review).Error; err != nil {
+		return err
+	}
+
+	return RefreshProductRating(tx, review.ProductID)
+}
+
+// RefreshProductRating recomputes the denormalized rating of a product from
+// its visible reviews. It runs in the same transaction as every review
+// change so catalog listings never disagree with the reviews themselves.
+func RefreshProductRating(tx *gorm.DB, productID uint) error {
+	var stats struct {
+		Average float64
+		Count   int
+	}
+
+	if err := tx.Model(&models.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("product_id = ? AND is_hidden = ?", productID, false).
+		Scan(&stats).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Product{}).Where("id = ?", productID).UpdateColumns(map[string]interface{}{
+		"average_rating": math.Round(stats.Average*100) / 100,
+		"review_count":   stats.Count,
+	}).Error
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	ErrSellerAlreadyApproved = errors.New("seller is already approved")
+	ErrApplicationNotPending = errors.New("application is not pending review")
+	ErrApplicationIncomplete = errors.New("application has no documents")
+	ErrInvalidSellerStatus   = errors.New("invalid seller status transition")
+)
+
+// SubmitSellerApplication creates or resubmits the seller's application and
+// puts the seller back into review. Approved sellers cannot resubmit.
+func SubmitSellerApplication(tx *gorm.DB, sellerID uint, details models.SellerApplication) (models.SellerApplication, error) {
+	var seller models.Seller
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&seller, sellerID).Error; err != nil {
+		return details, err
+	}
+
+	if seller.Status == utils.SellerStatusApproved || seller.Status == utils.SellerStatusSuspended {
+		return details, ErrSellerAlreadyApproved
+	}
+
+	var application models.SellerApplication
+	err := tx.Where("seller_id = ?", sellerID).First(&application).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return application, err
+	}
+
+	now := time.Now()
+	application.SellerID = sellerID
+	application.BusinessName = details.BusinessName
+	application.BusinessType = details.BusinessType
+	application.RegistrationNumber = details.RegistrationNumber
+	application.TaxID = details.TaxID
+	application.BusinessAddress = details.BusinessAddress
+	application.Status = utils.SellerStatusPending
+	application.ReviewNotes = ""
+	application.ReviewedBy = nil
+	application.ReviewedAt = nil
+	application.SubmittedAt = &now
+
+	if err := tx.Save(&application).Error; err != nil {
+		return application, err
+	}
+
+	if err := tx.Model(&seller).Updates(map[string]interface{}{
+		"status":        utils.SellerStatusPending,
+		"status_reason": "",
+	}).Error; err != nil {
+		return application, err
+	}
+
+	return application, nil
+}
+
+// ReviewSellerApplication approves or rejects a pending application and
+// carries the decision over to the seller.
+func ReviewSellerApplication(tx *gorm.DB, applicationID string, adminID uint, approve bool, notes string) (models.SellerApplication, error) {
+	var application models.SellerApplication
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Documents").First(&application, applicationID).Error; err != nil {
+		return application, err
+	}
+
+	if application.Status != utils.SellerStatusPending {
+		return application, ErrApplicationNotPending
+	}
+
+	if approve && len(application.Documents) == 0 {
+		return application, ErrApplicationIncomplete
+	}
+
+	status := utils.SellerStatusRejected
+	if approve {
+		status = utils.SellerStatusApproved
+	}
+
+	now := time.Now()
+	application.Status = status
+	application.ReviewNotes = notes
+	application.ReviewedBy = &adminID
+	application.ReviewedAt = &now
+
+	if err := tx.Omit("Documents").Save(&application).Error; err != nil {
+		return application, err
+	}
+
+	if err := tx.Model(&models.Seller{}).Where("id = ?", application.SellerID).Updates(map[string]interface{}{
+		"status":        status,
+		"status_reason": notes,
+	}).Error; err != nil {
+		return application, err
+	}
+
+	return application, nil
+}
+
+// SetSellerStatus suspends an approved seller or reinstates a suspended
+// one.
+func SetSellerStatus(tx *gorm.DB, sellerID string, status string, reason string) (models.Seller, error) {
+	var seller models.Seller
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&seller, sellerID).Error; err != nil {
+		return seller, err
+	}
+
+	switch {
+	case status == utils.SellerStatusSuspended && seller.Status == utils.SellerStatusApproved:
+	case status == utils.SellerStatusApproved && seller.Status == utils.SellerStatusSuspended:
+	default:
+		return seller, ErrInvalidSellerStatus
+	}
+
+	seller.Status = status
+	seller.StatusReason = reason
+	if err := tx.Model(&seller).Updates(map[string]interface{}{
+		"status":        seller.Status,
+		"status_reason": seller.StatusReason,
+	}).Error; err != nil {
+		return seller, err
+	}
+
+	return seller, nil
+}
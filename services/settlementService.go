@@ -0,0 +1,345 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrNothingToPayout       = errors.New("there are no settled earnings to pay out for this period")
+	ErrPayoutBatchNotPending = errors.New("payout batch has already been completed")
+)
+
+// payoutBatchLockKey identifies the advisory lock held while creating a
+// payout batch.
+const payoutBatchLockKey = 27001
+
+func DefaultCommissionRate() float64 {
+	rate, err := strconv.ParseFloat(os.Getenv("PLATFORM_COMMISSION_RATE"), 64)
+	if err != nil || rate < 0 {
+		return 0.10
+	}
+
+	return rate
+}
+
+func ResolveCommissionRate(db *gorm.DB, sellerID uint, category string) (float64, error) {
+	var rules []models.CommissionRule
+	if err := db.Where("seller_id = ? OR seller_id IS NULL", sellerID).Find(&rules).Error; err != nil {
+		return 0, err
+	}
+
+	rate := DefaultCommissionRate()
+	best := -1
+	for _, rule := range rules {
+		score := -1
+		switch {
+		case rule.SellerID != nil && rule.Category == category && category != "":
+			score = 3
+		case rule.SellerID != nil && rule.Category == "":
+			score = 2
+		case rule.SellerID == nil && rule.Category == category && category != "":
+			score = 1
+		case rule.SellerID == nil && rule.Category == "":
+			score = 0
+		}
+
+		if score > best {
+			best = score
+			rate = rule.Rate
+		}
+	}
+
+	return rate, nil
+}
+
+func SettleOrder(tx *gorm.DB, order models.Order, subOrders []models.SubOrder, discounts []AppliedDiscount) error {
+	platformFunded := platformFundedDiscounts(subOrders, discounts)
+
+	for _, subOrder := range subOrders {
+		gross := 0.0
+		commission := 0.0
+		shippingDiscount := subOrder.DiscountAmount
+		for _, item := range subOrder.CartItems {
+			rate, err := ResolveCommissionRate(tx, item.Product.SellerId, item.Product.Category)
+			if err != nil {
+				return err
+			}
+
+			lineTotal := NetLineAmount(item)
+			gross += lineTotal
+			commission += lineTotal * rate
+			shippingDiscount -= item.DiscountAmount
+		}
+
+		entries := []models.SellerLedgerEntry{
+			{
+				SellerID:    subOrder.SellerID,
+				OrderID:     &order.ID,
+				SubOrderID:  &subOrder.ID,
+				Type:        utils.LedgerEntrySale,
+				Amount:      utils.RoundMoney(gross),
+				Description: fmt.Sprintf("Sales for order #%d", order.ID),
+			},
+			{
+				SellerID:    subOrder.SellerID,
+				OrderID:     &order.ID,
+				SubOrderID:  &subOrder.ID,
+				Type:        utils.LedgerEntryCommission,
+				Amount:      -utils.RoundMoney(commission),
+				Description: fmt.Sprintf("Platform commission for order #%d", order.ID),
+			},
+		}
+
+		if shipping := utils.RoundMoney(subOrder.ShippingCost - shippingDiscount); shipping > 0 {
+			entries = append(entries, models.SellerLedgerEntry{
+				SellerID:    subOrder.SellerID,
+				OrderID:     &order.ID,
+				SubOrderID:  &subOrder.ID,
+				Type:        utils.LedgerEntryShipping,
+				Amount:      shipping,
+				Description: fmt.Sprintf("Shipping for order #%d", order.ID),
+			})
+		}
+
+		if funded := utils.RoundMoney(platformFunded[subOrder.SellerID]); funded > 0 {
+			entries = append(entries, models.SellerLedgerEntry{
+				SellerID:    subOrder.SellerID,
+				OrderID:     &order.ID,
+				SubOrderID:  &subOrder.ID,
+				Type:        utils.LedgerEntryDiscount,
+				Amount:      funded,
+				Description: fmt.Sprintf("Platform-funded discounts for order #%d", order.ID),
+			})
+		}
+
+		if err := tx.Create(&entries).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func platformFundedDiscounts(subOrders []models.SubOrder, discounts []AppliedDiscount) map[uint]float64 {
+	sellerByItem := map[uint]uint{}
+	for _, subOrder := range subOrders {
+		for _, item := range subOrder.CartItems {
+			sellerByItem[item.ID] = subOrder.SellerID
+		}
+	}
+
+	funded := map[uint]float64{}
+	for _, discount := range discounts {
+		if discount.SellerID != nil {
+			continue
+		}
+
+		for itemID, amount := range discount.LineAmounts {
+			funded[sellerByItem[itemID]] += amount
+		}
+		for sellerID, amount := range discount.ShippingAmounts {
+			funded[sellerID] += amount
+		}
+	}
+
+	return funded
+}
+
+// ReverseSettlement takes a refund or chargeback back out of the sellers'
+// earnings for the order. Refunds are recorded against the whole order, so
+// each seller gives back the same share of what the order settled to them.
+func ReverseSettlement(tx *gorm.DB, payment models.Payment, transactionType string, amount float64) error {
+	if payment.TotalAmount <= 0 {
+		return nil
+	}
+
+	var rows []struct {
+		SellerID uint
+		Amount   float64
+	}
+	if err := tx.Model(&models.SellerLedgerEntry{}).
+		Where("order_id = ? AND type IN ?", payment.OrderID, []string{utils.LedgerEntrySale, utils.LedgerEntryCommission, utils.LedgerEntryShipping, utils.LedgerEntryDiscount}).
+		Select("seller_id, SUM(amount) AS amount").Group("seller_id").Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	label := "Refund"
+	if transactionType == utils.PaymentTransactionChargeback {
+		label = "Chargeback"
+	}
+
+	share := amount / payment.TotalAmount
+	if share > 1 {
+		share = 1
+	}
+
+	for _, row := range rows {
+		reversed := utils.RoundMoney(row.Amount * share)
+		if reversed <= 0 {
+			continue
+		}
+
+		entry := models.SellerLedgerEntry{
+			SellerID:    row.SellerID,
+			OrderID:     &payment.OrderID,
+			Type:        utils.LedgerEntryReversal,
+			Amount:      -reversed,
+			Description: fmt.Sprintf("%s for order #%d", label, payment.OrderID),
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func SellerBalance(db *gorm.DB, sellerID interface{}) (float64, error) {
+	var balance float64
+	if err := db.Model(&models.SellerLedgerEntry{}).Where("seller_id = ?", sellerID).Select("COALESCE(SUM(amount), 0)").Scan(&balance).Error; err != nil {
+		return 0, err
+	}
+
+	return utils.RoundMoney(balance), nil
+}
+
+// settledPaymentStatuses are the payment states of orders whose ledger
+// entries can be paid out. Refunded orders stay eligible so their reversal
+// entries are netted against the seller's next payout.
+var settledPaymentStatuses = []string{utils.PaymentStatusPaid, utils.PaymentStatusPartiallyRefunded, utils.PaymentStatusRefunded}
+
+func CreatePayoutBatch(db *gorm.DB, periodEnd time.Time) (models.PayoutBatch, error) {
+	var batch models.PayoutBatch
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Batches are created one at a time so two admins cannot pay out the
+		// same ledger entries.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", payoutBatchLockKey).Error; err != nil {
+			return err
+		}
+
+		var lastBatch models.PayoutBatch
+		periodStart := time.Time{}
+		if err := tx.Order("period_end DESC").First(&lastBatch).Error; err == nil {
+			periodStart = lastBatch.PeriodEnd
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var entries []models.SellerLedgerEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("payout_id IS NULL AND type <> ? AND created_at <= ?", utils.LedgerEntryPayout, periodEnd).
+			Where("order_id IN (SELECT order_id FROM payments WHERE status IN ?)", settledPaymentStatuses).
+			Order("id").Find(&entries).Error; err != nil {
+			return err
+		}
+
+		var sellerIDs []uint
+		amounts := map[uint]float64{}
+		entryIDs := map[uint][]uint{}
+		for _, entry := range entries {
+			if _, seen := amounts[entry.SellerID]; !seen {
+				sellerIDs = append(sellerIDs, entry.SellerID)
+			}
+			amounts[entry.SellerID] += entry.Amount
+			entryIDs[entry.SellerID] = append(entryIDs[entry.SellerID], entry.ID)
+		}
+
+		batch = models.PayoutBatch{
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			Status:      utils.PayoutStatusPending,
+		}
+
+		for _, sellerID := range sellerIDs {
+			// Sellers who owe money after refunds carry it into a later
+			// payout.
+			amount := utils.RoundMoney(amounts[sellerID])
+			if amount <= 0 {
+				continue
+			}
+
+			if batch.ID == 0 {
+				if err := tx.Create(&batch).Error; err != nil {
+					return err
+				}
+			}
+
+			payout := models.Payout{
+				PayoutBatchID: batch.ID,
+				SellerID:      sellerID,
+				Amount:        amount,
+				Status:        utils.PayoutStatusPending,
+			}
+			if err := tx.Create(&payout).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.SellerLedgerEntry{}).Where("id IN ?", entryIDs[sellerID]).Update("payout_id", payout.ID).Error; err != nil {
+				return err
+			}
+
+			entry := models.SellerLedgerEntry{
+				SellerID:    sellerID,
+				PayoutID:    &payout.ID,
+				Type:        utils.LedgerEntryPayout,
+				Amount:      -payout.Amount,
+				Description: fmt.Sprintf("Payout #%d", payout.ID),
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+
+			batch.TotalAmount += payout.Amount
+		}
+
+		if batch.ID == 0 {
+			return ErrNothingToPayout
+		}
+
+		batch.TotalAmount = utils.RoundMoney(batch.TotalAmount)
+		return tx.Model(&batch).Update("total_amount", batch.TotalAmount).Error
+	})
+	if err != nil {
+		return batch, err
+	}
+
+	err = db.Preload("Payouts").First(&batch, batch.ID).Error
+	return batch, err
+}
+
+func CompletePayoutBatch(db *gorm.DB, batchID interface{}, reference string) (models.PayoutBatch, error) {
+	var batch models.PayoutBatch
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, batchID).Error; err != nil {
+			return err
+		}
+
+		if batch.Status != utils.PayoutStatusPending {
+			return ErrPayoutBatchNotPending
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.Payout{}).Where("payout_batch_id = ? AND status = ?", batch.ID, utils.PayoutStatusPending).
+			Updates(map[string]interface{}{"status": utils.PayoutStatusPaid, "paid_at": now, "reference": reference}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&batch).Update("status", utils.PayoutStatusPaid).Error
+	})
+	if err != nil {
+		return batch, err
+	}
+
+	err = db.Preload("Payouts").First(&batch, batch.ID).Error
+	return batch, err
+}
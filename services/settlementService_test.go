@@ -0,0 +1,136 @@
+package services_test
+
+import (
+	"api/models"
+	"api/services"
+	"api/utils"
+	"gorm.io/gorm"
+	"testing"
+)
+
+type ledgerKey struct {
+	sellerID  uint
+	entryType string
+}
+
+func ledger(t *testing.T, db *gorm.DB, orderID uint) map[ledgerKey]float64 {
+	t.Helper()
+
+	var entries []models.SellerLedgerEntry
+	if err := db.Where("order_id = ?", orderID).Find(&entries).Error; err != nil {
+		t.Fatalf("loading ledger: %v", err)
+	}
+
+	amounts := map[ledgerKey]float64{}
+	for _, entry := range entries {
+		amounts[ledgerKey{entry.SellerID, entry.Type}] = utils.RoundMoney(amounts[ledgerKey{entry.SellerID, entry.Type}] + entry.Amount)
+	}
+
+	return amounts
+}
+
+func TestSettleOrderAndReverseSettlement(t *testing.T) {
+	db := testDB(t)
+
+	customer := createCustomer(t, db, "settle-customer")
+	sellerA := createSeller(t, db, "settle-a")
+	sellerB := createSeller(t, db, "settle-b")
+	productA := createProduct(t, db, sellerA, "settle-a-product")
+	productB := createProduct(t, db, sellerB, "settle-b-product")
+	productB.Category = "books"
+
+	mustCreate(t, db, &models.CommissionRule{SellerID: &sellerA.ID, Rate: 0.2})
+	mustCreate(t, db, &models.CommissionRule{SellerID: &sellerB.ID, Rate: 0.3})
+	mustCreate(t, db, &models.CommissionRule{SellerID: &sellerB.ID, Category: "books", Rate: 0.15})
+
+	cart, items := createCart(t, db, customer, true, productA, productB)
+	var order models.Order
+	if err := db.Where("cart_id = ?", cart.ID).First(&order).Error; err != nil {
+		t.Fatalf("loading order: %v", err)
+	}
+
+	// Seller A: 2 x 10 with a 2.00 platform-funded line discount, and 5.00
+	// shipping with a 1.00 platform-funded shipping discount.
+	itemA := items[0]
+	itemA.Product = &productA
+	itemA.Quantity = 2
+	itemA.UnitPrice = 10
+	itemA.DiscountAmount = 2
+	subOrderA := models.SubOrder{OrderID: order.ID, SellerID: sellerA.ID, ShippingCost: 5, DiscountAmount: 3}
+	mustCreate(t, db, &subOrderA)
+	subOrderA.CartItems = []models.CartItem{itemA}
+
+	// Seller B: 30.00 including 5.00 tax, with a 5.00 discount the seller
+	// funds themselves, and free shipping.
+	itemB := items[1]
+	itemB.Product = &productB
+	itemB.UnitPrice = 30
+	itemB.DiscountAmount = 5
+	itemB.TaxInclusive = true
+	itemB.TaxAmount = 5
+	subOrderB := models.SubOrder{OrderID: order.ID, SellerID: sellerB.ID, DiscountAmount: 5}
+	mustCreate(t, db, &subOrderB)
+	subOrderB.CartItems = []models.CartItem{itemB}
+
+	discounts := []services.AppliedDiscount{
+		{LineAmounts: map[uint]float64{itemA.ID: 2}, ShippingAmounts: map[uint]float64{sellerA.ID: 1}},
+		{SellerID: &sellerB.ID, LineAmounts: map[uint]float64{itemB.ID: 5}},
+	}
+
+	if err := services.SettleOrder(db, order, []models.SubOrder{subOrderA, subOrderB}, discounts); err != nil {
+		t.Fatalf("SettleOrder: %v", err)
+	}
+
+	settled := map[ledgerKey]float64{
+		{sellerA.ID, utils.LedgerEntrySale}:       18,
+		{sellerA.ID, utils.LedgerEntryCommission}: -3.6,
+		{sellerA.ID, utils.LedgerEntryShipping}:   4,
+		{sellerA.ID, utils.LedgerEntryDiscount}:   3,
+		{sellerB.ID, utils.LedgerEntrySale}:       20,
+		{sellerB.ID, utils.LedgerEntryCommission}: -3,
+	}
+	got := ledger(t, db, order.ID)
+	if len(got) != len(settled) {
+		t.Errorf("ledger = %v, want %v", got, settled)
+	}
+	for key, want := range settled {
+		if got[key] != want {
+			t.Errorf("seller %d %s = %.2f, want %.2f", key.sellerID, key.entryType, got[key], want)
+		}
+	}
+
+	// A quarter of the order is refunded, so each seller gives back a
+	// quarter of what they earned: 21.40 and 17.00.
+	payment := models.Payment{OrderID: order.ID, TotalAmount: 100}
+	if err := services.ReverseSettlement(db, payment, utils.PaymentTransactionRefund, 25); err != nil {
+		t.Fatalf("ReverseSettlement: %v", err)
+	}
+
+	got = ledger(t, db, order.ID)
+	if reversed := got[ledgerKey{sellerA.ID, utils.LedgerEntryReversal}]; reversed != -5.35 {
+		t.Errorf("seller A reversal = %.2f, want -5.35", reversed)
+	}
+	if reversed := got[ledgerKey{sellerB.ID, utils.LedgerEntryReversal}]; reversed != -4.25 {
+		t.Errorf("seller B reversal = %.2f, want -4.25", reversed)
+	}
+
+	// Reversals are not part of what was settled, and a chargeback larger
+	// than the order takes back at most everything.
+	if err := services.ReverseSettlement(db, payment, utils.PaymentTransactionChargeback, 150); err != nil {
+		t.Fatalf("ReverseSettlement: %v", err)
+	}
+
+	got = ledger(t, db, order.ID)
+	if reversed := got[ledgerKey{sellerA.ID, utils.LedgerEntryReversal}]; reversed != -26.75 {
+		t.Errorf("seller A reversals = %.2f, want -26.75", reversed)
+	}
+	if reversed := got[ledgerKey{sellerB.ID, utils.LedgerEntryReversal}]; reversed != -21.25 {
+		t.Errorf("seller B reversals = %.2f, want -21.25", reversed)
+	}
+}
+
+func TestReverseSettlementWithoutTotal(t *testing.T) {
+	if err := services.ReverseSettlement(nil, models.Payment{}, utils.PaymentTransactionRefund, 10); err != nil {
+		t.Errorf("ReverseSettlement of a free order = %v, want nil", err)
+	}
+}
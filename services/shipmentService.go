@@ -0,0 +1,218 @@
+package services
+
+import (
+	"api/events"
+	"api/models"
+	"api/utils"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+var (
+	ErrShipmentItemNotInSubOrder = errors.New("shipment item doesn't belong to this sub-order")
+	ErrShipmentQuantityExceeded  = errors.New("shipment quantity exceeds the unshipped quantity of the order line")
+	ErrEmptyShipment             = errors.New("shipment must contain at least one item")
+	ErrShipmentItemNotPending    = errors.New("only pending order lines can be shipped")
+)
+
+type ShipmentLine struct {
+	CartItemID uint
+	Quantity   int
+}
+
+func CreateShipment(db *gorm.DB, subOrder models.SubOrder, carrier string, trackingNumber string, lines []ShipmentLine) (models.Shipment, error) {
+	var shipment models.Shipment
+	if len(lines) == 0 {
+		return shipment, ErrEmptyShipment
+	}
+
+	if _, err := GetCarrier(carrier); err != nil {
+		return shipment, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Concurrent shipments of one sub-order wait here, so each sees the
+		// quantities the others shipped.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subOrder, subOrder.ID).Error; err != nil {
+			return err
+		}
+
+		var cartItems []models.CartItem
+		if err := tx.Preload("Product", IncludeDeleted).Where("sub_order_id = ?", subOrder.ID).Order("id").Find(&cartItems).Error; err != nil {
+			return err
+		}
+
+		remaining := map[uint]int{}
+		statuses := map[uint]string{}
+		for _, item := range cartItems {
+			remaining[item.ID] = item.Quantity
+			statuses[item.ID] = item.Status
+		}
+
+		var shipped []struct {
+			CartItemID uint
+			Quantity   int
+		}
+		if err := tx.Model(&models.ShipmentItem{}).
+			Select("cart_item_id, SUM(quantity) AS quantity").
+			Where("shipment_id IN (?)", tx.Model(&models.Shipment{}).Select("id").Where("sub_order_id = ?", subOrder.ID)).
+			Group("cart_item_id").Scan(&shipped).Error; err != nil {
+			return err
+		}
+		for _, row := range shipped {
+			remaining[row.CartItemID] -= row.Quantity
+		}
+
+		now := time.Now()
+		shipment = models.Shipment{
+			SubOrderID:     subOrder.ID,
+			OrderID:        subOrder.OrderID,
+			SellerID:       subOrder.SellerID,
+			Carrier:        carrier,
+			TrackingNumber: trackingNumber,
+			Status:         utils.StatusShipped,
+			ShippedAt:      &now,
+		}
+
+		for _, line := range lines {
+			left, ok := remaining[line.CartItemID]
+			if !ok {
+				return ErrShipmentItemNotInSubOrder
+			}
+			if statuses[line.CartItemID] != utils.StatusPending {
+				return ErrShipmentItemNotPending
+			}
+			if line.Quantity <= 0 || line.Quantity > left {
+				return ErrShipmentQuantityExceeded
+			}
+
+			remaining[line.CartItemID] -= line.Quantity
+			shipment.Items = append(shipment.Items, models.ShipmentItem{CartItemID: line.CartItemID, Quantity: line.Quantity})
+		}
+
+		if err := tx.Create(&shipment).Error; err != nil {
+			return err
+		}
+
+		for _, item := range cartItems {
+			if remaining[item.ID] == 0 && item.Status == utils.StatusPending {
+				if err := setItemStatus(tx, subOrder.OrderID, item, utils.StatusShipped); err != nil {
+					return err
+				}
+			}
+		}
+
+		return RefreshSubOrderStatus(tx, subOrder.ID)
+	})
+
+	return shipment, err
+}
+
+func MarkShipmentDelivered(db *gorm.DB, shipment *models.Shipment, deliveredAt time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		shipment.Status = utils.StatusDelivered
+		shipment.DeliveredAt = &deliveredAt
+		if err := tx.Save(shipment).Error; err != nil {
+			return err
+		}
+
+		var deliveredItems []models.CartItem
+		if err := tx.Preload("Product", IncludeDeleted).
+			Where("id IN (?)", tx.Model(&models.ShipmentItem{}).Select("cart_item_id").Where("shipment_id = ?", shipment.ID)).
+			Order("id").Find(&deliveredItems).Error; err != nil {
+			return err
+		}
+
+		for _, item := range deliveredItems {
+			var undelivered int64
+			if err := tx.Model(&models.ShipmentItem{}).
+				Joins("JOIN shipments ON shipments.id = shipment_items.shipment_id").
+				Where("shipment_items.cart_item_id = ? AND shipments.delivered_at IS NULL AND shipments.deleted_at IS NULL", item.ID).
+				Count(&undelivered).Error; err != nil {
+				return err
+			}
+
+			if undelivered == 0 && item.Status == utils.StatusShipped {
+				if err := setItemStatus(tx, shipment.OrderID, item, utils.StatusDelivered); err != nil {
+					return err
+				}
+			}
+		}
+
+		return RefreshSubOrderStatus(tx, shipment.SubOrderID)
+	})
+}
+
+func setItemStatus(tx *gorm.DB, orderID uint, item models.CartItem, status string) error {
+	previousStatus := item.Status
+	item.Status = status
+	if err := tx.Model(&models.CartItem{}).Where("id = ?", item.ID).Update("status", status).Error; err != nil {
+		return err
+	}
+
+	return events.RecordOrderItemStatusChange(tx, orderID, item, previousStatus)
+}
+
+// SyncUndeliveredShipments pulls tracking updates for every shipment that
+// has not been delivered yet and returns how many failed to sync. Shipments
+// of carriers that are no longer registered are skipped.
+func SyncUndeliveredShipments(db *gorm.DB) (int, error) {
+	var shipments []models.Shipment
+	if err := db.Where("delivered_at IS NULL").Order("id").Find(&shipments).Error; err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	for i := range shipments {
+		if err := SyncShipmentTracking(db, &shipments[i]); err != nil && !errors.Is(err, ErrUnknownCarrier) {
+			log.Printf("Could not sync tracking for shipment %d: %v", shipments[i].ID, err)
+			failed++
+		}
+	}
+
+	return failed, nil
+}
+
+func SyncShipmentTracking(db *gorm.DB, shipment *models.Shipment) error {
+	carrier, err := GetCarrier(shipment.Carrier)
+	if err != nil {
+		return err
+	}
+
+	updates, err := carrier.Track(shipment.TrackingNumber)
+	if err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		var count int64
+		if err := db.Model(&models.TrackingEvent{}).Where("shipment_id = ? AND status = ? AND occurred_at = ?", shipment.ID, update.Status, update.OccurredAt).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		event := models.TrackingEvent{
+			ShipmentID:  shipment.ID,
+			Status:      update.Status,
+			Description: update.Description,
+			Location:    update.Location,
+			OccurredAt:  update.OccurredAt,
+		}
+		if err := db.Create(&event).Error; err != nil {
+			return err
+		}
+
+		if update.Status == utils.StatusDelivered && shipment.DeliveredAt == nil {
+			if err := MarkShipmentDelivered(db, shipment, update.OccurredAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
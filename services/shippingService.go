@@ -0,0 +1,158 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"math"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrNoShippingOption      = errors.New("no shipping method is available for this destination")
+	ErrInvalidShippingMethod = errors.New("selected shipping method is not available for this seller and destination")
+)
+
+const volumetricDivisor = 5000.0
+
+type ShippingOption struct {
+	RateID uint    `json:"rate_id"`
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Cost   float64 `json:"cost"`
+}
+
+type SellerShippingQuote struct {
+	SellerID uint             `json:"seller_id"`
+	Subtotal float64          `json:"subtotal"`
+	WeightKg float64          `json:"weight_kg"`
+	Options  []ShippingOption `json:"options"`
+}
+
+func ChargeableWeight(product models.Product) float64 {
+	volumetric := product.LengthCm * product.WidthCm * product.HeightCm / volumetricDivisor
+	return math.Max(product.WeightKg, volumetric)
+}
+
+func RateCost(rate models.ShippingRate, subtotal float64, weight float64) (float64, bool) {
+	if weight < rate.MinWeight || (rate.MaxWeight > 0 && weight > rate.MaxWeight) {
+		return 0, false
+	}
+
+	switch rate.Type {
+	case utils.ShippingRateFlat:
+		return utils.RoundMoney(rate.Amount), true
+	case utils.ShippingRateWeight:
+		return utils.RoundMoney(rate.Amount + rate.PerKg*math.Ceil(weight)), true
+	case utils.ShippingRateFreeOver:
+		if subtotal >= rate.FreeThreshold {
+			return 0, true
+		}
+		return utils.RoundMoney(rate.Amount), true
+	}
+
+	return 0, false
+}
+
+func QuoteShipping(db *gorm.DB, cartItems []models.CartItem, country string) ([]SellerShippingQuote, error) {
+	var quotes []SellerShippingQuote
+	indexBySeller := map[uint]int{}
+
+	for _, item := range cartItems {
+		index, ok := indexBySeller[item.Product.SellerId]
+		if !ok {
+			quotes = append(quotes, SellerShippingQuote{SellerID: item.Product.SellerId})
+			index = len(quotes) - 1
+			indexBySeller[item.Product.SellerId] = index
+		}
+
+		quotes[index].Subtotal += float64(item.Quantity) * item.Product.Price
+		quotes[index].WeightKg += float64(item.Quantity) * ChargeableWeight(*item.Product)
+	}
+
+	for i := range quotes {
+		quotes[i].Subtotal = utils.RoundMoney(quotes[i].Subtotal)
+		quotes[i].WeightKg = math.Round(quotes[i].WeightKg*1000) / 1000
+
+		var zones []models.ShippingZone
+		if err := db.Preload("Rates").Where("seller_id = ?", quotes[i].SellerID).Find(&zones).Error; err != nil {
+			return nil, err
+		}
+
+		if len(zones) == 0 {
+			quotes[i].Options = []ShippingOption{{Name: "Standard", Type: utils.ShippingRateFlat, Cost: 0}}
+			continue
+		}
+
+		zone := matchShippingZone(zones, country)
+		if zone == nil {
+			continue
+		}
+
+		for _, rate := range zone.Rates {
+			cost, ok := RateCost(rate, quotes[i].Subtotal, quotes[i].WeightKg)
+			if !ok {
+				continue
+			}
+
+			quotes[i].Options = append(quotes[i].Options, ShippingOption{RateID: rate.ID, Name: rate.Name, Type: rate.Type, Cost: cost})
+		}
+
+		sort.SliceStable(quotes[i].Options, func(a, b int) bool {
+			return quotes[i].Options[a].Cost < quotes[i].Options[b].Cost
+		})
+	}
+
+	return quotes, nil
+}
+
+func SelectShipping(quotes []SellerShippingQuote, chosen map[uint]uint) (map[uint]ShippingOption, error) {
+	selected := map[uint]ShippingOption{}
+
+	for _, quote := range quotes {
+		if len(quote.Options) == 0 {
+			return nil, fmt.Errorf("%w (seller %d)", ErrNoShippingOption, quote.SellerID)
+		}
+
+		rateID, ok := chosen[quote.SellerID]
+		if !ok {
+			selected[quote.SellerID] = quote.Options[0]
+			continue
+		}
+
+		found := false
+		for _, option := range quote.Options {
+			if option.RateID == rateID {
+				selected[quote.SellerID] = option
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w (seller %d)", ErrInvalidShippingMethod, quote.SellerID)
+		}
+	}
+
+	return selected, nil
+}
+
+func matchShippingZone(zones []models.ShippingZone, country string) *models.ShippingZone {
+	var fallback *models.ShippingZone
+	for i := range zones {
+		for _, code := range strings.Split(zones[i].Countries, ",") {
+			code = strings.ToUpper(strings.TrimSpace(code))
+			if code == country {
+				return &zones[i]
+			}
+			if code == "*" && fallback == nil {
+				fallback = &zones[i]
+			}
+		}
+	}
+
+	return fallback
+}
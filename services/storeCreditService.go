@@ -0,0 +1,179 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrGiftCardNotFound    = errors.New("gift card code is not valid")
+	ErrGiftCardRedeemed    = errors.New("gift card has already been redeemed")
+	ErrGiftCardExpired     = errors.New("gift card has expired")
+	ErrInsufficientCredit  = errors.New("insufficient store credit")
+	ErrInvalidCreditAmount = errors.New("store credit amount must be greater than zero")
+)
+
+const (
+	giftCardCodeAlphabet    = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	giftCardCodeGroupLength = 4
+	giftCardCodeGroups      = 4
+)
+
+func GenerateGiftCardCode() (string, error) {
+	var groups []string
+	for i := 0; i < giftCardCodeGroups; i++ {
+		var group strings.Builder
+		for j := 0; j < giftCardCodeGroupLength; j++ {
+			index, err := rand.Int(rand.Reader, big.NewInt(int64(len(giftCardCodeAlphabet))))
+			if err != nil {
+				return "", err
+			}
+			group.WriteByte(giftCardCodeAlphabet[index.Int64()])
+		}
+		groups = append(groups, group.String())
+	}
+
+	return strings.Join(groups, "-"), nil
+}
+
+func IssueGiftCard(db *gorm.DB, adminID uint, amount float64, expiresAt *time.Time, note string) (models.GiftCard, error) {
+	var giftCard models.GiftCard
+
+	amount = utils.RoundMoney(amount)
+	if amount <= 0 {
+		return giftCard, ErrInvalidCreditAmount
+	}
+
+	code, err := GenerateGiftCardCode()
+	if err != nil {
+		return giftCard, err
+	}
+
+	giftCard = models.GiftCard{
+		Code:           code,
+		InitialBalance: amount,
+		Balance:        amount,
+		Note:           note,
+		IssuedByID:     adminID,
+		ExpiresAt:      expiresAt,
+	}
+
+	return giftCard, db.Create(&giftCard).Error
+}
+
+func RedeemGiftCard(db *gorm.DB, customerID uint, code string) (models.StoreCreditEntry, error) {
+	var entry models.StoreCreditEntry
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var giftCard models.GiftCard
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", strings.ToUpper(strings.TrimSpace(code))).First(&giftCard).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrGiftCardNotFound
+			}
+			return err
+		}
+
+		if giftCard.RedeemedAt != nil || giftCard.Balance <= 0 {
+			return ErrGiftCardRedeemed
+		}
+		if giftCard.ExpiresAt != nil && time.Now().After(*giftCard.ExpiresAt) {
+			return ErrGiftCardExpired
+		}
+
+		amount := giftCard.Balance
+		now := time.Now()
+		giftCard.Balance = 0
+		giftCard.RedeemedByID = &customerID
+		giftCard.RedeemedAt = &now
+		if err := tx.Save(&giftCard).Error; err != nil {
+			return err
+		}
+
+		var err error
+		entry, err = adjustStoreCredit(tx, customerID, amount, utils.StoreCreditGiftCardRedemption, &giftCard.ID, nil, fmt.Sprintf("Redeemed gift card %s", maskGiftCardCode(giftCard.Code)))
+		return err
+	})
+
+	return entry, err
+}
+
+func CreditStoreCredit(tx *gorm.DB, customerID uint, amount float64, entryType string, orderID *uint, description string) (models.StoreCreditEntry, error) {
+	amount = utils.RoundMoney(amount)
+	if amount <= 0 {
+		return models.StoreCreditEntry{}, ErrInvalidCreditAmount
+	}
+
+	return adjustStoreCredit(tx, customerID, amount, entryType, nil, orderID, description)
+}
+
+func SpendStoreCredit(tx *gorm.DB, customerID uint, maxAmount float64, orderID uint) (float64, error) {
+	var customer models.Customer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, customerID).Error; err != nil {
+		return 0, err
+	}
+
+	amount := utils.RoundMoney(minFloat(customer.StoreCreditBalance, maxAmount))
+	if amount <= 0 {
+		return 0, nil
+	}
+
+	if _, err := adjustStoreCredit(tx, customerID, -amount, utils.StoreCreditOrderPayment, nil, &orderID, fmt.Sprintf("Applied to order #%d", orderID)); err != nil {
+		return 0, err
+	}
+
+	return amount, nil
+}
+
+func adjustStoreCredit(tx *gorm.DB, customerID uint, amount float64, entryType string, giftCardID *uint, orderID *uint, description string) (models.StoreCreditEntry, error) {
+	var entry models.StoreCreditEntry
+
+	var customer models.Customer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, customerID).Error; err != nil {
+		return entry, err
+	}
+
+	balance := utils.RoundMoney(customer.StoreCreditBalance + amount)
+	if balance < 0 {
+		return entry, ErrInsufficientCredit
+	}
+
+	if err := tx.Model(&customer).Update("store_credit_balance", balance).Error; err != nil {
+		return entry, err
+	}
+
+	entry = models.StoreCreditEntry{
+		CustomerID:   customerID,
+		Type:         entryType,
+		Amount:       utils.RoundMoney(amount),
+		BalanceAfter: balance,
+		GiftCardID:   giftCardID,
+		OrderID:      orderID,
+		Description:  description,
+	}
+
+	return entry, tx.Create(&entry).Error
+}
+
+func maskGiftCardCode(code string) string {
+	if len(code) <= 4 {
+		return code
+	}
+
+	return strings.Repeat("*", len(code)-4) + code[len(code)-4:]
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
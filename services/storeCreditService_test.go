@@ -0,0 +1,100 @@
+package services_test
+
+import (
+	"api/models"
+	"api/services"
+	"api/utils"
+	"errors"
+	"testing"
+)
+
+func TestStoreCreditSpendAndRefund(t *testing.T) {
+	db := testDB(t)
+	customer := createCustomer(t, db, "credit-customer")
+	orderID := uint(1)
+
+	giftCard, err := services.IssueGiftCard(db, 1, 30, nil, "")
+	if err != nil {
+		t.Fatalf("IssueGiftCard: %v", err)
+	}
+	if _, err := services.RedeemGiftCard(db, customer.ID, " "+giftCard.Code+" "); err != nil {
+		t.Fatalf("RedeemGiftCard: %v", err)
+	}
+	if _, err := services.RedeemGiftCard(db, customer.ID, giftCard.Code); !errors.Is(err, services.ErrGiftCardRedeemed) {
+		t.Errorf("redeeming twice = %v, want ErrGiftCardRedeemed", err)
+	}
+
+	steps := []struct {
+		name      string
+		run       func() (float64, error)
+		want      float64
+		entryType string
+		balance   float64
+	}{
+		{"apply part of the credit", func() (float64, error) {
+			return services.SpendStoreCredit(db, customer.ID, 12.5, orderID)
+		}, 12.5, utils.StoreCreditOrderPayment, 17.5},
+		{"apply at most the balance", func() (float64, error) {
+			return services.SpendStoreCredit(db, customer.ID, 45, orderID)
+		}, 17.5, utils.StoreCreditOrderPayment, 0},
+		{"refund to credit", func() (float64, error) {
+			entry, err := services.CreditStoreCredit(db, customer.ID, 20.004, utils.StoreCreditRefund, &orderID, "Refund")
+			return entry.Amount, err
+		}, 20, utils.StoreCreditRefund, 20},
+	}
+
+	for _, step := range steps {
+		got, err := step.run()
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: amount = %.2f, want %.2f", step.name, got, step.want)
+		}
+
+		var entry models.StoreCreditEntry
+		if err := db.Where("customer_id = ?", customer.ID).Order("id DESC").First(&entry).Error; err != nil {
+			t.Fatalf("%s: loading entry: %v", step.name, err)
+		}
+		if entry.Type != step.entryType || entry.BalanceAfter != step.balance {
+			t.Errorf("%s: entry %s with balance %.2f, want %s with %.2f", step.name, entry.Type, entry.BalanceAfter, step.entryType, step.balance)
+		}
+
+		if err := db.First(&customer, customer.ID).Error; err != nil {
+			t.Fatalf("%s: loading customer: %v", step.name, err)
+		}
+		if customer.StoreCreditBalance != step.balance {
+			t.Errorf("%s: balance = %.2f, want %.2f", step.name, customer.StoreCreditBalance, step.balance)
+		}
+	}
+
+	if _, err := services.CreditStoreCredit(db, customer.ID, 0.004, utils.StoreCreditRefund, &orderID, "Refund"); !errors.Is(err, services.ErrInvalidCreditAmount) {
+		t.Errorf("crediting less than a cent = %v, want ErrInvalidCreditAmount", err)
+	}
+
+	var entries int64
+	if err := db.Model(&models.StoreCreditEntry{}).Where("customer_id = ?", customer.ID).Count(&entries).Error; err != nil {
+		t.Fatalf("counting entries: %v", err)
+	}
+	if entries != 4 {
+		t.Errorf("got %d store credit entries, want 4", entries)
+	}
+}
+
+func TestSpendStoreCreditWithoutBalance(t *testing.T) {
+	db := testDB(t)
+	customer := createCustomer(t, db, "credit-empty")
+
+	spent, err := services.SpendStoreCredit(db, customer.ID, 10, 1)
+	if err != nil || spent != 0 {
+		t.Errorf("SpendStoreCredit = %.2f, %v, want nothing spent", spent, err)
+	}
+
+	var entries int64
+	if err := db.Model(&models.StoreCreditEntry{}).Where("customer_id = ?", customer.ID).Count(&entries).Error; err != nil {
+		t.Fatalf("counting entries: %v", err)
+	}
+	if entries != 0 {
+		t.Errorf("spending no credit wrote %d entries", entries)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"math"
+)
+
+var (
+	ErrSubOrderNotDelivered = errors.New("sub-order has not been delivered")
+	ErrSellerAlreadyRated   = errors.New("sub-order has already been rated")
+)
+
+// UniqueStoreSlug derives a slug from the store name, suffixing it with a
+// counter when another seller already uses it.
+func UniqueStoreSlug(db *gorm.DB, value string, sellerID uint) (string, error) {
+	base := utils.Slugify(value)
+	if base == "" {
+		base = "store"
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := db.Unscoped().Model(&models.Seller{}).Where("store_slug = ? AND id <> ?", slug, sellerID).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return slug, nil
+		}
+
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// VisibleProducts scopes a product query to what the public catalog may
+// show.
+func VisibleProducts(db *gorm.DB) *gorm.DB {
+	return db.Where("products.status = ?", utils.ProductStatusPublished).
+		Where("products.seller_id IN (SELECT id FROM sellers WHERE status = ? AND store_status = ? AND deleted_at IS NULL)", utils.SellerStatusApproved, utils.StoreStatusOpen)
+}
+
+func RateSeller(tx *gorm.DB, rating *models.SellerRating) error {
+	var subOrder models.SubOrder
+	if err := tx.Where("order_id IN (SELECT orders.id FROM orders JOIN carts ON carts.id = orders.cart_id WHERE carts.customer_id = ?)", rating.CustomerID).
+		First(&subOrder, rating.SubOrderID).Error; err != nil {
+		return err
+	}
+
+	if subOrder.Status != utils.StatusDelivered {
+		return ErrSubOrderNotDelivered
+	}
+
+	var count int64
+	if err := tx.Model(&models.SellerRating{}).Where("sub_order_id = ?", subOrder.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSellerAlreadyRated
+	}
+
+	rating.SellerID = subOrder.SellerID
+	if err := tx.Create(rating).Error; err != nil {
+		return err
+	}
+
+	return RefreshSellerRating(tx, subOrder.SellerID)
+}
+
+func RefreshSellerRating(tx *gorm.DB, sellerID uint) error {
+	var stats struct {
+		Average float64
+		Count   int
+	}
+
+	if err := tx.Model(&models.SellerRating{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("seller_id = ?", sellerID).
+		Scan(&stats).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Seller{}).Where("id = ?", sellerID).UpdateColumns(map[string]interface{}{
+		"average_rating": math.Round(stats.Average*100) / 100,
+		"rating_count":   stats.Count,
+	}).Error
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"gorm.io/gorm"
+	"sync"
+)
+
+type TaxLine struct {
+	Key      uint
+	TaxClass string
+	Amount   float64
+}
+
+type TaxRequest struct {
+	Address models.AddressFields
+	Lines   []TaxLine
+}
+
+type TaxLineResult struct {
+	Key       uint    `json:"key"`
+	Rate      float64 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+	TaxAmount float64 `json:"tax_amount"`
+}
+
+type TaxResult struct {
+	Lines        []TaxLineResult `json:"lines"`
+	TotalTax     float64         `json:"total_tax"`
+	ExclusiveTax float64         `json:"exclusive_tax"`
+}
+
+type TaxCalculator interface {
+	Calculate(db *gorm.DB, request TaxRequest) (TaxResult, error)
+}
+
+var (
+	taxCalculatorMu sync.RWMutex
+	taxCalculator   TaxCalculator = DatabaseTaxCalculator{}
+)
+
+func SetTaxCalculator(calculator TaxCalculator) {
+	taxCalculatorMu.Lock()
+	defer taxCalculatorMu.Unlock()
+
+	taxCalculator = calculator
+}
+
+func CalculateTax(db *gorm.DB, request TaxRequest) (TaxResult, error) {
+	taxCalculatorMu.RLock()
+	calculator := taxCalculator
+	taxCalculatorMu.RUnlock()
+
+	return calculator.Calculate(db, request)
+}
+
+type DatabaseTaxCalculator struct{}
+
+func (DatabaseTaxCalculator) Calculate(db *gorm.DB, request TaxRequest) (TaxResult, error) {
+	var result TaxResult
+
+	var rates []models.TaxRate
+	if err := db.Where("country = ? AND (region = ? OR region = '')", request.Address.Country, request.Address.Region).Find(&rates).Error; err != nil {
+		return result, err
+	}
+
+	for _, line := range request.Lines {
+		taxClass := line.TaxClass
+		if taxClass == "" {
+			taxClass = utils.DefaultTaxClass
+		}
+
+		lineResult := TaxLineResult{Key: line.Key}
+		if rate := matchTaxRate(rates, taxClass); rate != nil {
+			lineResult.Rate = rate.Rate
+			lineResult.Inclusive = rate.Inclusive
+			if rate.Inclusive {
+				lineResult.TaxAmount = utils.RoundMoney(line.Amount - line.Amount/(1+rate.Rate))
+			} else {
+				lineResult.TaxAmount = utils.RoundMoney(line.Amount * rate.Rate)
+				result.ExclusiveTax += lineResult.TaxAmount
+			}
+		}
+
+		result.TotalTax += lineResult.TaxAmount
+		result.Lines = append(result.Lines, lineResult)
+	}
+
+	result.TotalTax = utils.RoundMoney(result.TotalTax)
+	result.ExclusiveTax = utils.RoundMoney(result.ExclusiveTax)
+
+	return result, nil
+}
+
+func matchTaxRate(rates []models.TaxRate, taxClass string) *models.TaxRate {
+	var match *models.TaxRate
+	for i := range rates {
+		if rates[i].TaxClass != taxClass {
+			continue
+		}
+
+		if match == nil || (match.Region == "" && rates[i].Region != "") {
+			match = &rates[i]
+		}
+	}
+
+	return match
+}
+
+func CartTaxRequest(cartItems []models.CartItem, address models.AddressFields) TaxRequest {
+	request := TaxRequest{Address: address}
+	for _, item := range cartItems {
+		request.Lines = append(request.Lines, TaxLine{
+			Key:      item.ID,
+			TaxClass: item.Product.TaxClass,
+			Amount:   float64(item.Quantity)*item.Product.Price - item.DiscountAmount,
+		})
+	}
+
+	return request
+}
+
+func ApplyTaxToCartItems(cartItems []models.CartItem, result TaxResult) {
+	byKey := map[uint]TaxLineResult{}
+	for _, line := range result.Lines {
+		byKey[line.Key] = line
+	}
+
+	for i := range cartItems {
+		line := byKey[cartItems[i].ID]
+		cartItems[i].UnitPrice = cartItems[i].Product.Price
+		cartItems[i].TaxRate = line.Rate
+		cartItems[i].TaxAmount = line.TaxAmount
+		cartItems[i].TaxInclusive = line.Inclusive
+	}
+}
+
+func NetLineAmount(item models.CartItem) float64 {
+	amount := float64(item.Quantity)*item.UnitPrice - item.DiscountAmount
+	if item.TaxInclusive {
+		amount -= item.TaxAmount
+	}
+
+	return amount
+}
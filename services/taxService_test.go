@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"api/models"
+	"api/services"
+	"testing"
+)
+
+func TestDatabaseTaxCalculator(t *testing.T) {
+	db := testDB(t)
+
+	for _, rate := range []models.TaxRate{
+		{Name: "Standard", Country: "ZZ", TaxClass: "standard", Rate: 0.1},
+		{Name: "Standard North", Country: "ZZ", Region: "North", TaxClass: "standard", Rate: 0.2},
+		{Name: "Reduced", Country: "ZZ", TaxClass: "reduced", Rate: 0.05},
+		{Name: "Food", Country: "ZZ", TaxClass: "food", Rate: 0.25, Inclusive: true},
+	} {
+		mustCreate(t, db, &rate)
+	}
+
+	lines := []services.TaxLine{
+		{Key: 1, TaxClass: "standard", Amount: 100},
+		{Key: 2, Amount: 33.33},
+		{Key: 3, TaxClass: "reduced", Amount: 10},
+		{Key: 4, TaxClass: "food", Amount: 125},
+		{Key: 5, TaxClass: "luxury", Amount: 50},
+	}
+
+	cases := []struct {
+		name         string
+		address      models.AddressFields
+		want         []services.TaxLineResult
+		totalTax     float64
+		exclusiveTax float64
+	}{
+		{
+			name:    "regional rate overrides the country rate",
+			address: models.AddressFields{Country: "ZZ", Region: "North"},
+			want: []services.TaxLineResult{
+				{Key: 1, Rate: 0.2, TaxAmount: 20},
+				{Key: 2, Rate: 0.2, TaxAmount: 6.67},
+				{Key: 3, Rate: 0.05, TaxAmount: 0.5},
+				{Key: 4, Rate: 0.25, Inclusive: true, TaxAmount: 25},
+				{Key: 5},
+			},
+			totalTax:     52.17,
+			exclusiveTax: 27.17,
+		},
+		{
+			name:    "country rate without a regional one",
+			address: models.AddressFields{Country: "ZZ", Region: "South"},
+			want: []services.TaxLineResult{
+				{Key: 1, Rate: 0.1, TaxAmount: 10},
+				{Key: 2, Rate: 0.1, TaxAmount: 3.33},
+				{Key: 3, Rate: 0.05, TaxAmount: 0.5},
+				{Key: 4, Rate: 0.25, Inclusive: true, TaxAmount: 25},
+				{Key: 5},
+			},
+			totalTax:     38.83,
+			exclusiveTax: 13.83,
+		},
+		{
+			name:    "country without rates",
+			address: models.AddressFields{Country: "ZY", Region: "North"},
+			want:    []services.TaxLineResult{{Key: 1}, {Key: 2}, {Key: 3}, {Key: 4}, {Key: 5}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := services.DatabaseTaxCalculator{}.Calculate(db, services.TaxRequest{Address: c.address, Lines: lines})
+			if err != nil {
+				t.Fatalf("Calculate: %v", err)
+			}
+
+			if len(result.Lines) != len(c.want) {
+				t.Fatalf("got %d lines, want %d", len(result.Lines), len(c.want))
+			}
+			for i, want := range c.want {
+				if result.Lines[i] != want {
+					t.Errorf("line %d = %+v, want %+v", want.Key, result.Lines[i], want)
+				}
+			}
+			if result.TotalTax != c.totalTax || result.ExclusiveTax != c.exclusiveTax {
+				t.Errorf("total tax %.2f (exclusive %.2f), want %.2f (%.2f)", result.TotalTax, result.ExclusiveTax, c.totalTax, c.exclusiveTax)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package services_test
+
+import (
+	"api/migrations"
+	"api/models"
+	"api/utils"
+	"fmt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	testDBOnce sync.Once
+	testConn   *gorm.DB
+	testDBErr  error
+)
+
+// testDB returns a transaction on the database in TEST_DATABASE_DSN that is
+// rolled back when the test ends. Tests that need it are skipped when the
+// variable is not set.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	testDBOnce.Do(func() {
+		testConn, testDBErr = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if testDBErr == nil {
+			testDBErr = migrations.Migrate(testConn)
+		}
+	})
+	if testDBErr != nil {
+		t.Fatalf("preparing test database: %v", testDBErr)
+	}
+
+	tx := testConn.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	return tx
+}
+
+func mustCreate(t *testing.T, db *gorm.DB, record interface{}) {
+	t.Helper()
+
+	if err := db.Create(record).Error; err != nil {
+		t.Fatalf("creating %T: %v", record, err)
+	}
+}
+
+func createSeller(t *testing.T, db *gorm.DB, name string) models.Seller {
+	t.Helper()
+
+	seller := models.Seller{
+		User: models.User{
+			Email: fmt.Sprintf("%s-%d@sellers.test", name, time.Now().UnixNano()),
+			Phone: fmt.Sprintf("s%d", time.Now().UnixNano()),
+			Name:  name,
+		},
+		StoreName:   name,
+		Status:      utils.SellerStatusApproved,
+		StoreStatus: utils.StoreStatusOpen,
+	}
+	mustCreate(t, db, &seller)
+
+	return seller
+}
+
+func createCustomer(t *testing.T, db *gorm.DB, name string) models.Customer {
+	t.Helper()
+
+	customer := models.Customer{
+		User: models.User{
+			Email: fmt.Sprintf("%s-%d@customers.test", name, time.Now().UnixNano()),
+			Phone: fmt.Sprintf("c%d", time.Now().UnixNano()),
+			Name:  name,
+		},
+	}
+	mustCreate(t, db, &customer)
+
+	return customer
+}
+
+func createProduct(t *testing.T, db *gorm.DB, seller models.Seller, name string) models.Product {
+	t.Helper()
+
+	product := models.Product{
+		Name:     name,
+		SKU:      fmt.Sprintf("%s-%d", name, time.Now().UnixNano()),
+		Price:    10,
+		Status:   utils.ProductStatusPublished,
+		SellerId: seller.ID,
+	}
+	mustCreate(t, db, &product)
+
+	return product
+}
+
+// createCart puts one of each product in a new cart and places an order for
+// it when ordered is set.
+func createCart(t *testing.T, db *gorm.DB, customer models.Customer, ordered bool, products ...models.Product) (models.Cart, []models.CartItem) {
+	t.Helper()
+
+	cart := models.Cart{CustomerID: &customer.ID, IsActive: !ordered}
+	mustCreate(t, db, &cart)
+
+	var items []models.CartItem
+	for _, product := range products {
+		item := models.CartItem{CartID: cart.ID, ProductID: product.ID, Quantity: 1, UnitPrice: product.Price, Status: utils.StatusPending}
+		mustCreate(t, db, &item)
+		items = append(items, item)
+	}
+
+	if ordered {
+		mustCreate(t, db, &models.Order{CartID: cart.ID, OrderedDate: time.Now(), Status: utils.StatusPending})
+	}
+
+	return cart, items
+}
+
+// softDeleteAt soft deletes record as if it happened at deletedAt.
+func softDeleteAt(t *testing.T, db *gorm.DB, record interface{}, deletedAt time.Time) {
+	t.Helper()
+
+	if err := db.Unscoped().Model(record).Update("deleted_at", deletedAt).Error; err != nil {
+		t.Fatalf("soft deleting %T: %v", record, err)
+	}
+}
+
+func exists(t *testing.T, db *gorm.DB, model interface{}, id uint) bool {
+	t.Helper()
+
+	var count int64
+	if err := db.Unscoped().Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		t.Fatalf("counting %T: %v", model, err)
+	}
+
+	return count > 0
+}
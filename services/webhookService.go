@@ -0,0 +1,346 @@
+package services
+
+import (
+	"api/events"
+	"api/models"
+	"api/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	webhookTimeout         = 10 * time.Second
+	webhookResponseMaxSize = 2048
+)
+
+var ErrUnsafeWebhookURL = errors.New("webhook URL must use https and resolve to a public address")
+
+// webhookClient only dials public addresses, so a host that resolves to an
+// internal address after the endpoint was registered is still refused.
+// Redirects are not followed.
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: webhookTimeout,
+			Control: publicAddressOnly,
+		}).DialContext,
+		TLSHandshakeTimeout: webhookTimeout,
+	},
+	CheckRedirect: func(request *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// ValidateWebhookURL rejects endpoints that are not https or whose host
+// resolves to a loopback, private, link-local or otherwise internal
+// address.
+func ValidateWebhookURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return ErrUnsafeWebhookURL
+	}
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil || len(addresses) == 0 {
+		return ErrUnsafeWebhookURL
+	}
+
+	for _, address := range addresses {
+		if !isPublicIP(address.IP) {
+			return ErrUnsafeWebhookURL
+		}
+	}
+
+	return nil
+}
+
+func publicAddressOnly(network string, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return ErrUnsafeWebhookURL
+	}
+
+	return nil
+}
+
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// WebhookMaxFailures is how many delivery attempts in a row may fail before
+// the endpoint is disabled, from WEBHOOK_MAX_FAILURES (default 15).
+func WebhookMaxFailures() int {
+	maxFailures, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_FAILURES"))
+	if err != nil || maxFailures < 1 {
+		return 15
+	}
+
+	return maxFailures
+}
+
+func WebhookSubscribes(endpoint models.WebhookEndpoint, eventType string) bool {
+	for _, subscribed := range strings.Split(endpoint.EventTypes, ",") {
+		if subscribed == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func NewWebhookSecret() (string, error) {
+	token, err := utils.RandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	return "whsec_" + token, nil
+}
+
+// CreateWebhookDeliveries stores one pending delivery per active endpoint
+// subscribed to the event. Deliveries are unique per endpoint and event, so
+// an event the relay publishes twice is only delivered once; only the newly
+// created deliveries are returned.
+func CreateWebhookDeliveries(tx *gorm.DB, event events.Event) ([]models.WebhookDelivery, error) {
+	if len(event.SellerIDs) == 0 {
+		return nil, nil
+	}
+
+	var endpoints []models.WebhookEndpoint
+	if err := tx.Where("seller_id IN ? AND is_active = ?", event.SellerIDs, true).Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, endpoint := range endpoints {
+		if !WebhookSubscribes(endpoint, event.Type) {
+			continue
+		}
+
+		sellerEvent, err := event.ForSeller(endpoint.SellerID)
+		if err != nil {
+			return nil, err
+		}
+
+		payload, err := json.Marshal(sellerEvent)
+		if err != nil {
+			return nil, err
+		}
+
+		delivery := models.WebhookDelivery{
+			WebhookEndpointID: endpoint.ID,
+			EventID:           event.ID,
+			EventType:         event.Type,
+			Payload:           string(payload),
+			Status:            utils.WebhookDeliveryPending,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&delivery)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	return deliveries, nil
+}
+
+// DeliverWebhook attempts a stored delivery once. A failed attempt returns
+// an error so the job queue retries it; final marks the last attempt, after
+// which the delivery is recorded as failed. Every failed attempt counts
+// towards disabling the endpoint.
+func DeliverWebhook(ctx context.Context, db *gorm.DB, deliveryID uint, final bool) error {
+	var delivery models.WebhookDelivery
+	if err := db.Preload("WebhookEndpoint").First(&delivery, deliveryID).Error; err != nil {
+		return err
+	}
+
+	if delivery.Status != utils.WebhookDeliveryPending {
+		return nil
+	}
+
+	if delivery.WebhookEndpoint == nil || !delivery.WebhookEndpoint.IsActive {
+		delivery.Status = utils.WebhookDeliveryFailed
+		delivery.LastError = "webhook endpoint is disabled"
+		return db.Omit("WebhookEndpoint").Save(&delivery).Error
+	}
+
+	endpoint := *delivery.WebhookEndpoint
+	sendErr := sendWebhook(ctx, endpoint, &delivery)
+	if sendErr != nil && final {
+		delivery.Status = utils.WebhookDeliveryFailed
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("WebhookEndpoint").Save(&delivery).Error; err != nil {
+			return err
+		}
+
+		return recordWebhookResult(tx, endpoint.ID, sendErr)
+	})
+	if err != nil {
+		return err
+	}
+
+	return sendErr
+}
+
+// SendTestWebhook delivers a webhook.test event to the endpoint right away
+// and returns the logged delivery. Test deliveries are not retried and do
+// not count towards disabling the endpoint.
+func SendTestWebhook(ctx context.Context, db *gorm.DB, endpoint models.WebhookEndpoint) (models.WebhookDelivery, error) {
+	eventID, err := utils.RandomToken()
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	data, err := json.Marshal(map[string]string{"message": "This is a test event."})
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	payload, err := json.Marshal(events.Event{
+		ID:            eventID,
+		Type:          "webhook.test",
+		Version:       1,
+		AggregateType: "webhook_endpoint",
+		AggregateID:   endpoint.ID,
+		SellerIDs:     []uint{endpoint.SellerID},
+		OccurredAt:    time.Now(),
+		Data:          data,
+	})
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookEndpointID: endpoint.ID,
+		EventID:           eventID,
+		EventType:         "webhook.test",
+		Payload:           string(payload),
+		IsTest:            true,
+		Status:            utils.WebhookDeliveryPending,
+	}
+
+	if err := db.Create(&delivery).Error; err != nil {
+		return delivery, err
+	}
+
+	if err := sendWebhook(ctx, endpoint, &delivery); err != nil {
+		delivery.Status = utils.WebhookDeliveryFailed
+	}
+
+	return delivery, db.Save(&delivery).Error
+}
+
+func sendWebhook(ctx context.Context, endpoint models.WebhookEndpoint, delivery *models.WebhookDelivery) error {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = 0
+	delivery.ResponseBody = ""
+
+	err := postWebhook(ctx, endpoint, delivery)
+	delivery.DurationMs = time.Since(now).Milliseconds()
+	if err != nil {
+		delivery.LastError = err.Error()
+		return err
+	}
+
+	delivery.Status = utils.WebhookDeliverySucceeded
+	delivery.LastError = ""
+	delivery.DeliveredAt = &now
+
+	return nil
+}
+
+func postWebhook(ctx context.Context, endpoint models.WebhookEndpoint, delivery *models.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Simple-ECommerce-Webhooks/1.0")
+	request.Header.Set("X-Webhook-Id", strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set("X-Event-Id", delivery.EventID)
+	request.Header.Set("X-Event-Type", delivery.EventType)
+	request.Header.Set("X-Webhook-Timestamp", timestamp)
+	// The timestamp is signed with the body so receivers can reject replays.
+	request.Header.Set("X-Webhook-Signature", events.Sign(endpoint.Secret, append([]byte(timestamp+"."), body...)))
+
+	response, err := webhookClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(response.Body, webhookResponseMaxSize))
+	delivery.ResponseStatus = response.StatusCode
+	delivery.ResponseBody = string(responseBody)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("endpoint responded with %s", response.Status)
+	}
+
+	return nil
+}
+
+func recordWebhookResult(tx *gorm.DB, endpointID uint, sendErr error) error {
+	var endpoint models.WebhookEndpoint
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&endpoint, endpointID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if sendErr == nil {
+		return tx.Model(&endpoint).Update("consecutive_failures", 0).Error
+	}
+
+	endpoint.ConsecutiveFailures++
+	if endpoint.IsActive && endpoint.ConsecutiveFailures >= WebhookMaxFailures() {
+		now := time.Now()
+		endpoint.IsActive = false
+		endpoint.DisabledAt = &now
+		endpoint.DisabledReason = fmt.Sprintf("disabled after %d failed delivery attempts in a row: %v", endpoint.ConsecutiveFailures, sendErr)
+	}
+
+	return tx.Model(&endpoint).Select("consecutive_failures", "is_active", "disabled_at", "disabled_reason").Updates(&endpoint).Error
+}
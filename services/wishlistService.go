@@ -0,0 +1,175 @@
+package services
+
+import (
+	"api/models"
+	"api/utils"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"sync"
+	"time"
+)
+
+const SaveForLaterWishlistName = "Saved for later"
+
+type PriceDrop struct {
+	Wishlist models.Wishlist
+	Item     models.WishlistItem
+	Product  models.Product
+	OldPrice float64
+}
+
+// PriceDropHook is called once per wishlist entry whose product became
+// cheaper than it was when the entry was added.
+type PriceDropHook func(drop PriceDrop)
+
+var (
+	priceDropHooksMu sync.RWMutex
+	priceDropHooks   []PriceDropHook
+)
+
+func init() {
+	RegisterPriceDropHook(func(drop PriceDrop) {
+		log.Printf("wishlist %d: %s dropped from %.2f to %.2f", drop.Wishlist.ID, drop.Product.Name, drop.OldPrice, drop.Product.Price)
+	})
+}
+
+func RegisterPriceDropHook(hook PriceDropHook) {
+	priceDropHooksMu.Lock()
+	defer priceDropHooksMu.Unlock()
+
+	priceDropHooks = append(priceDropHooks, hook)
+}
+
+func NotifyPriceDrop(db *gorm.DB, product models.Product, oldPrice float64) error {
+	if product.Price >= oldPrice {
+		return nil
+	}
+
+	var items []models.WishlistItem
+	if err := db.Preload("Wishlist").Where("product_id = ? AND added_price > ?", product.ID, product.Price).Find(&items).Error; err != nil {
+		return err
+	}
+
+	priceDropHooksMu.RLock()
+	defer priceDropHooksMu.RUnlock()
+
+	for _, item := range items {
+		if item.Wishlist == nil {
+			continue
+		}
+		for _, hook := range priceDropHooks {
+			hook(PriceDrop{Wishlist: *item.Wishlist, Item: item, Product: product, OldPrice: oldPrice})
+		}
+	}
+
+	return nil
+}
+
+func CreateWishlist(tx *gorm.DB, customerID uint, name string, isPublic bool, isSaveForLater bool) (models.Wishlist, error) {
+	token, err := utils.RandomToken()
+	if err != nil {
+		return models.Wishlist{}, err
+	}
+
+	wishlist := models.Wishlist{
+		CustomerID:     customerID,
+		Name:           name,
+		IsPublic:       isPublic,
+		IsSaveForLater: isSaveForLater,
+		ShareToken:     token,
+	}
+	err = tx.Create(&wishlist).Error
+
+	return wishlist, err
+}
+
+func SaveForLaterWishlist(tx *gorm.DB, customerID uint) (models.Wishlist, error) {
+	var wishlist models.Wishlist
+
+	err := tx.Where("customer_id = ? AND is_save_for_later = ?", customerID, true).First(&wishlist).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return CreateWishlist(tx, customerID, SaveForLaterWishlistName, false, true)
+	}
+
+	return wishlist, err
+}
+
+// AddWishlistItem adds quantity to the product's entry on the wishlist. An
+// entry removed by moving it to the cart is brought back as a new one,
+// since the product can only be listed once per wishlist.
+func AddWishlistItem(tx *gorm.DB, wishlistID uint, product models.Product, quantity int) (models.WishlistItem, error) {
+	var item models.WishlistItem
+
+	err := tx.Unscoped().Where("wishlist_id = ? AND product_id = ?", wishlistID, product.ID).First(&item).Error
+	if err == nil {
+		if item.DeletedAt.Valid {
+			item.DeletedAt = gorm.DeletedAt{}
+			item.CreatedAt = time.Now()
+			item.Quantity = 0
+			item.AddedPrice = product.Price
+		}
+		item.Quantity += quantity
+		return item, tx.Unscoped().Save(&item).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return item, err
+	}
+
+	item = models.WishlistItem{
+		WishlistID: wishlistID,
+		ProductID:  product.ID,
+		Quantity:   quantity,
+		AddedPrice: product.Price,
+	}
+
+	return item, tx.Create(&item).Error
+}
+
+func MoveWishlistItemToCart(tx *gorm.DB, item models.WishlistItem, cartID uint) error {
+	var cartItem models.CartItem
+
+	err := tx.Where("cart_id = ? AND product_id = ?", cartID, item.ProductID).First(&cartItem).Error
+	switch {
+	case err == nil:
+		cartItem.Quantity += item.Quantity
+		if err := tx.Save(&cartItem).Error; err != nil {
+			return err
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		cartItem = models.CartItem{
+			CartID:    cartID,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+		if err := tx.Create(&cartItem).Error; err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	if err := TouchCart(tx, cartID); err != nil {
+		return err
+	}
+
+	return tx.Delete(&item).Error
+}
+
+func SaveCartItemForLater(tx *gorm.DB, cartItem models.CartItem, wishlistID uint) (models.WishlistItem, error) {
+	var product models.Product
+	if err := tx.First(&product, cartItem.ProductID).Error; err != nil {
+		return models.WishlistItem{}, err
+	}
+
+	item, err := AddWishlistItem(tx, wishlistID, product, cartItem.Quantity)
+	if err != nil {
+		return item, err
+	}
+
+	if err := TouchCart(tx, cartItem.CartID); err != nil {
+		return item, err
+	}
+
+	return item, tx.Delete(&cartItem).Error
+}
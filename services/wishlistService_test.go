@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"api/models"
+	"api/services"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWishlistItemRoundTripThroughCart(t *testing.T) {
+	db := testDB(t)
+
+	seller := createSeller(t, db, "wishlist-seller")
+	customer := createCustomer(t, db, "wishlist-customer")
+	product := createProduct(t, db, seller, "wishlist-product")
+	cart, _ := createCart(t, db, customer, false)
+
+	wishlist := models.Wishlist{CustomerID: customer.ID, Name: "Later", ShareToken: fmt.Sprintf("wishlist-%d", time.Now().UnixNano())}
+	mustCreate(t, db, &wishlist)
+
+	item, err := services.AddWishlistItem(db, wishlist.ID, product, 2)
+	if err != nil {
+		t.Fatalf("AddWishlistItem: %v", err)
+	}
+
+	if err := services.MoveWishlistItemToCart(db, item, cart.ID); err != nil {
+		t.Fatalf("MoveWishlistItemToCart: %v", err)
+	}
+	if !exists(t, db, &models.WishlistItem{}, item.ID) {
+		t.Fatal("moving the item to the cart hard deleted it")
+	}
+
+	var cartItem models.CartItem
+	if err := db.Where("cart_id = ? AND product_id = ?", cart.ID, product.ID).First(&cartItem).Error; err != nil {
+		t.Fatalf("loading cart item: %v", err)
+	}
+	if cartItem.Quantity != 2 {
+		t.Errorf("cart quantity = %d, want 2", cartItem.Quantity)
+	}
+
+	saved, err := services.SaveCartItemForLater(db, cartItem, wishlist.ID)
+	if err != nil {
+		t.Fatalf("SaveCartItemForLater: %v", err)
+	}
+	if saved.ID != item.ID || saved.DeletedAt.Valid || saved.Quantity != 2 {
+		t.Errorf("saved item = %+v, want item %d back with quantity 2", saved, item.ID)
+	}
+	if !exists(t, db, &models.CartItem{}, cartItem.ID) {
+		t.Error("saving the item for later hard deleted the cart item")
+	}
+
+	var live int64
+	if err := db.Model(&models.CartItem{}).Where("cart_id = ?", cart.ID).Count(&live).Error; err != nil {
+		t.Fatalf("counting cart items: %v", err)
+	}
+	if live != 0 {
+		t.Errorf("cart still has %d live items", live)
+	}
+}
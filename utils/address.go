@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"api/models"
+	"regexp"
+	"strings"
+)
+
+var postalCodeFormats = map[string]*regexp.Regexp{
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"CA": regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"EG": regexp.MustCompile(`^\d{5}$`),
+	"SA": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"IN": regexp.MustCompile(`^\d{6}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+}
+
+var regionRequiredCountries = map[string]bool{
+	"US": true,
+	"CA": true,
+	"AU": true,
+	"IN": true,
+}
+
+var countryCodeFormat = regexp.MustCompile(`^[A-Z]{2}$`)
+var phoneFormat = regexp.MustCompile(`^\+?[0-9 ()-]{6,20}$`)
+
+func NormalizeAddress(address models.AddressFields) models.AddressFields {
+	address.FullName = strings.TrimSpace(address.FullName)
+	address.Line1 = strings.TrimSpace(address.Line1)
+	address.Line2 = strings.TrimSpace(address.Line2)
+	address.City = strings.TrimSpace(address.City)
+	address.Region = strings.TrimSpace(address.Region)
+	address.PostalCode = strings.ToUpper(strings.TrimSpace(address.PostalCode))
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+	address.Phone = strings.TrimSpace(address.Phone)
+
+	return address
+}
+
+func ValidateAddress(address models.AddressFields) []ValidationError {
+	var errs []ValidationError
+
+	required := map[string]string{
+		"full_name": address.FullName,
+		"line1":     address.Line1,
+		"city":      address.City,
+		"country":   address.Country,
+	}
+	for _, field := range []string{"full_name", "line1", "city", "country"} {
+		if required[field] == "" {
+			errs = append(errs, ValidationError{Field: field, Reason: "required"})
+		}
+	}
+
+	if address.Country != "" && !countryCodeFormat.MatchString(address.Country) {
+		errs = append(errs, ValidationError{Field: "country", Reason: "iso3166_1_alpha2"})
+	}
+
+	if regionRequiredCountries[address.Country] && address.Region == "" {
+		errs = append(errs, ValidationError{Field: "region", Reason: "required"})
+	}
+
+	if format, ok := postalCodeFormats[address.Country]; ok {
+		if address.PostalCode == "" {
+			errs = append(errs, ValidationError{Field: "postal_code", Reason: "required"})
+		} else if !format.MatchString(address.PostalCode) {
+			errs = append(errs, ValidationError{Field: "postal_code", Reason: "postal_code_" + address.Country})
+		}
+	}
+
+	if address.Phone != "" && !phoneFormat.MatchString(address.Phone) {
+		errs = append(errs, ValidationError{Field: "phone", Reason: "phone"})
+	}
+
+	return errs
+}
+
+func FormatAddress(address models.AddressFields) string {
+	var parts []string
+	for _, part := range []string{address.FullName, address.Line1, address.Line2, address.City, strings.TrimSpace(address.Region + " " + address.PostalCode), address.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const CartTokenHeader = "X-Cart-Token"
+
+var ErrInvalidCartToken = errors.New("invalid cart token")
+
+func cartTokenKey() []byte {
+	if secret := os.Getenv("CART_TOKEN_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+
+	return jwtKey
+}
+
+func RandomToken() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+func SignCartToken(cartID uint, nonce string) string {
+	payload := fmt.Sprintf("%d.%s", cartID, nonce)
+	return payload + "." + cartTokenSignature(payload)
+}
+
+func ParseCartToken(token string) (uint, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, "", ErrInvalidCartToken
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(cartTokenSignature(payload)), []byte(parts[2])) {
+		return 0, "", ErrInvalidCartToken
+	}
+
+	cartID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", ErrInvalidCartToken
+	}
+
+	return uint(cartID), parts[1], nil
+}
+
+func cartTokenSignature(payload string) string {
+	mac := hmac.New(sha256.New, cartTokenKey())
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -1,8 +1,114 @@
 package utils
 
 const (
-	StatusPending   = "Pending"
-	StatusShipped   = "Shipped"
-	StatusDelivered = "Delivered"
-	StatusCancelled = "Cancelled"
+	StatusPending          = "Pending"
+	StatusPartiallyShipped = "Partially Shipped"
+	StatusShipped          = "Shipped"
+	StatusDelivered        = "Delivered"
+	StatusCancelled        = "Cancelled"
+)
+
+const (
+	PaymentTransactionAuthorization = "authorization"
+	PaymentTransactionCapture       = "capture"
+	PaymentTransactionRefund        = "refund"
+	PaymentTransactionChargeback    = "chargeback"
+)
+
+const (
+	PaymentStatusUnpaid            = "unpaid"
+	PaymentStatusAuthorized        = "authorized"
+	PaymentStatusPartiallyPaid     = "partially_paid"
+	PaymentStatusPaid              = "paid"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+	PaymentStatusRefunded          = "refunded"
+)
+
+const (
+	LedgerEntrySale       = "sale"
+	LedgerEntryCommission = "commission"
+	LedgerEntryShipping   = "shipping"
+	LedgerEntryDiscount   = "discount"
+	LedgerEntryReversal   = "reversal"
+	LedgerEntryPayout     = "payout"
+)
+
+const (
+	PayoutStatusPending = "pending"
+	PayoutStatusPaid    = "paid"
+)
+
+const (
+	ShippingRateFlat     = "flat"
+	ShippingRateWeight   = "weight"
+	ShippingRateFreeOver = "free_over"
+)
+
+const DefaultTaxClass = "standard"
+
+const (
+	PromotionPercentage   = "percentage"
+	PromotionFixed        = "fixed"
+	PromotionBuyXGetY     = "buy_x_get_y"
+	PromotionFreeShipping = "free_shipping"
+)
+
+const PaymentMethodStoreCredit = "store_credit"
+
+const (
+	StoreCreditGiftCardRedemption = "gift_card_redemption"
+	StoreCreditOrderPayment       = "order_payment"
+	StoreCreditRefund             = "refund"
+)
+
+const (
+	CartMergeSum      = "sum"
+	CartMergeMax      = "max"
+	CartMergeGuest    = "guest"
+	CartMergeCustomer = "customer"
+)
+
+const (
+	StoreStatusOpen     = "open"
+	StoreStatusVacation = "vacation"
+)
+
+const (
+	SellerStatusPending   = "pending"
+	SellerStatusApproved  = "approved"
+	SellerStatusRejected  = "rejected"
+	SellerStatusSuspended = "suspended"
+)
+
+const (
+	ProductStatusDraft         = "draft"
+	ProductStatusPendingReview = "pending_review"
+	ProductStatusPublished     = "published"
+	ProductStatusRejected      = "rejected"
+	ProductStatusArchived      = "archived"
+)
+
+const (
+	ImportStatusPending    = "pending"
+	ImportStatusProcessing = "processing"
+	ImportStatusCompleted  = "completed"
+	ImportStatusFailed     = "failed"
+)
+
+const (
+	FileFormatCSV   = "csv"
+	FileFormatJSONL = "jsonl"
+)
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusDead      = "dead"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
 )
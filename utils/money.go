@@ -0,0 +1,7 @@
+package utils
+
+import "math"
+
+func RoundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -35,6 +35,10 @@ func UnauthorizedRequestJson(c *gin.Context, error string) {
 	ErrorJSON(c, http.StatusUnauthorized, gin.H{"message": error})
 }
 
+func ForbiddenRequestErrorJson(c *gin.Context, error string) {
+	ErrorJSON(c, http.StatusForbidden, gin.H{"message": error})
+}
+
 func NotFoundRequestErrorJson(c *gin.Context, error string) {
 	ErrorJSON(c, http.StatusNotFound, gin.H{"message": error})
 }
@@ -46,3 +50,7 @@ func ConflictRequestErrorJson(c *gin.Context, error string) {
 func ValidationErrorJson(c *gin.Context, verr validator.ValidationErrors) {
 	ErrorJSON(c, http.StatusUnprocessableEntity, gin.H{"errors": HandleValidationErrors(verr)})
 }
+
+func AddressValidationErrorJson(c *gin.Context, errs []ValidationError) {
+	ErrorJSON(c, http.StatusUnprocessableEntity, gin.H{"errors": errs})
+}
@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func Slugify(value string) string {
+	slug := nonSlugChars.ReplaceAllString(strings.ToLower(value), "-")
+	return strings.Trim(slug, "-")
+}